@@ -3,43 +3,74 @@ package ai
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/config"
 )
 
+// Used when no max_tokens parameter is configured, as the Messages API requires one
+const defaultAnthropicMaxTokens = 1024
+
+// Smallest thinking budget the Messages API accepts
+const minAnthropicThinkingBudget = 1024
+
 type AnthropicProvider struct {
 	client anthropic.Client
 	model  string
+	params config.Parameters
+	caps   config.ModelCapabilities
 }
 
-func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
+func NewAnthropicProvider(apiKey, model string, opts Options) *AnthropicProvider {
 	client := anthropic.NewClient(option.WithAPIKey(apiKey))
 	return &AnthropicProvider{
 		client: client,
 		model:  model,
+		params: supportedParameters(config.ProviderAnthropic, model, opts.Capabilities, opts.Parameters),
+		caps:   opts.Capabilities,
 	}
 }
 
-func (p *AnthropicProvider) Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error) {
-	systemPrompt := BuildSystemPrompt(sysInfo)
-	userPrompt := BuildUserPrompt(question)
+func (p *AnthropicProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
+	systemPrompt, userPrompt = promptsForModel(p.caps, systemPrompt, userPrompt)
 
-	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+	request := anthropic.MessageNewParams{
 		Model:     anthropic.Model(p.model),
-		MaxTokens: 1024,
-		System: []anthropic.TextBlockParam{
+		MaxTokens: defaultAnthropicMaxTokens,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	}
+	if systemPrompt != "" {
+		request.System = []anthropic.TextBlockParam{
 			{
 				Type: "text",
 				Text: systemPrompt,
 			},
-		},
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
-		},
-	})
+		}
+	}
+	if p.params.MaxTokens != nil {
+		request.MaxTokens = *p.params.MaxTokens
+	}
+	if p.params.Temperature != nil {
+		request.Temperature = anthropic.Float(*p.params.Temperature)
+	}
+	if p.params.TopP != nil {
+		request.TopP = anthropic.Float(*p.params.TopP)
+	}
+	if _, ok := thinkingBudgets[p.params.Reasoning]; ok {
+		maxTokens, budget, err := anthropicThinking(p.params)
+		if err != nil {
+			return nil, err
+		}
+		request.MaxTokens = maxTokens
+		request.Thinking = anthropic.ThinkingConfigParamOfEnabled(budget)
+	}
 
+	message, err := p.client.Messages.New(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("anthropic API error: %w", err)
 	}
@@ -49,11 +80,15 @@ func (p *AnthropicProvider) Ask(ctx context.Context, question string, sysInfo *s
 	}
 
 	var responseText string
+	var thinking []string
 	for _, block := range message.Content {
-		textBlock := block.AsText()
-		if textBlock.Type == "text" {
-			responseText = textBlock.Text
-			break
+		switch block.Type {
+		case "text":
+			if responseText == "" {
+				responseText = block.AsText().Text
+			}
+		case "thinking":
+			thinking = append(thinking, block.AsThinking().Thinking)
 		}
 	}
 
@@ -61,7 +96,29 @@ func (p *AnthropicProvider) Ask(ctx context.Context, question string, sysInfo *s
 		return nil, fmt.Errorf("no text content in Anthropic response")
 	}
 
-	return ParseResponse(responseText), nil
+	response := ParseResponse(responseText)
+	response.Reasoning = strings.TrimSpace(strings.Join(thinking, "\n\n"))
+	response.Usage = Usage{InputTokens: message.Usage.InputTokens, OutputTokens: message.Usage.OutputTokens}
+	return response, nil
+}
+
+// Fit the thinking budget for the reasoning level into max_tokens, which
+// includes it. Without a configured max_tokens, room is added for the
+// answer; otherwise the budget is lowered to half of it
+func anthropicThinking(params config.Parameters) (int64, int64, error) {
+	budget := thinkingBudgets[params.Reasoning]
+	if params.MaxTokens == nil {
+		return budget + defaultAnthropicMaxTokens, budget, nil
+	}
+
+	maxTokens := *params.MaxTokens
+	if maxTokens <= budget {
+		budget = maxTokens / 2
+		if budget < minAnthropicThinkingBudget {
+			return 0, 0, fmt.Errorf("max_tokens must be at least %d with reasoning on", 2*minAnthropicThinkingBudget)
+		}
+	}
+	return maxTokens, budget, nil
 }
 
 func (p *AnthropicProvider) GetName() string {
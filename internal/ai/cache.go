@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/connorgannaway/how/internal/cache"
+)
+
+// Provider that answers repeated requests from an on-disk cache
+type cachedProvider struct {
+	Provider
+	cache   *cache.Cache
+	model   string
+	baseURL string
+	options Options
+}
+
+// Wrap a provider so identical requests reuse cached responses. Requests are
+// keyed by the system prompt, user prompt, provider, model, base URL and the
+// options the provider was created with
+func NewCachedProvider(provider Provider, responseCache *cache.Cache, model, baseURL string, options Options) Provider {
+	return &cachedProvider{
+		Provider: provider,
+		cache:    responseCache,
+		model:    model,
+		baseURL:  baseURL,
+		options:  options,
+	}
+}
+
+func (p *cachedProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
+	key, err := cache.Key(systemPrompt, userPrompt, p.GetName(), p.model, p.baseURL, p.options)
+	if err != nil {
+		return p.Provider.Ask(ctx, req)
+	}
+
+	if !req.Refresh {
+		if data, ok := p.cache.Get(key); ok {
+			var response Response
+			if err := json.Unmarshal(data, &response); err == nil {
+				// No tokens were used to answer this time
+				response.Cached = true
+				response.Usage = Usage{}
+				return &response, nil
+			}
+		}
+	}
+
+	response, err := p.Provider.Ask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replaces any cached response. A failed write only costs a future cache hit
+	if data, err := json.Marshal(response); err == nil {
+		p.cache.Put(key, data)
+	}
+	return response, nil
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/connorgannaway/how/internal/cache"
+	"github.com/connorgannaway/how/internal/config"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Provider that counts the requests it answers
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	p.calls++
+	return &Response{Commands: []string{"echo " + req.Question}, Usage: Usage{InputTokens: 10}}, nil
+}
+
+func (p *countingProvider) GetName() string {
+	return config.ProviderOpenAI
+}
+
+func TestCachedProvider(t *testing.T) {
+	responseCache, err := cache.New(t.TempDir(), time.Hour, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := &countingProvider{}
+	provider := NewCachedProvider(base, responseCache, "gpt-5", "", Options{})
+	request := func() *Request {
+		return &Request{Question: "list files", SysInfo: &system.SystemInfo{OS: "linux", Shell: "bash"}}
+	}
+
+	first, err := provider.Ask(context.Background(), request())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached {
+		t.Error("first answer marked cached")
+	}
+
+	second, err := provider.Ask(context.Background(), request())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || second.Usage != (Usage{}) || base.calls != 1 {
+		t.Errorf("second answer = %+v after %d calls, want a cached answer without usage", second, base.calls)
+	}
+
+	refresh := request()
+	refresh.Refresh = true
+	if response, _ := provider.Ask(context.Background(), refresh); response.Cached || base.calls != 2 {
+		t.Error("refresh answered from the cache")
+	}
+}
+
+func TestCachedProviderKey(t *testing.T) {
+	responseCache, err := cache.New(t.TempDir(), time.Hour, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &Request{Question: "list files", SysInfo: &system.SystemInfo{OS: "linux", Shell: "bash"}}
+
+	variants := []struct {
+		name    string
+		model   string
+		baseURL string
+		options Options
+	}{
+		{"base", "gpt-5", "", Options{}},
+		{"model", "gpt-5-mini", "", Options{}},
+		{"base URL", "gpt-5", "http://localhost:11434/v1", Options{}},
+		{"parameters", "gpt-5", "", Options{Parameters: config.Parameters{Reasoning: config.ReasoningHigh}}},
+		{"capabilities", "gpt-5", "", Options{Capabilities: config.ModelCapabilities{Reasoning: true}}},
+		{"responses API", "gpt-5", "", Options{UseResponsesAPI: true}},
+		{"google vertex", "gpt-5", "", Options{Google: config.GoogleSettings{Vertex: true, Project: "p"}}},
+		{"google safety", "gpt-5", "", Options{Google: config.GoogleSettings{SafetySettings: map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_NONE"}}}},
+	}
+
+	// Every variant is a separate entry, so each one misses the cache once
+	base := &countingProvider{}
+	for _, variant := range variants {
+		provider := NewCachedProvider(base, responseCache, variant.model, variant.baseURL, variant.options)
+		if response, err := provider.Ask(context.Background(), request); err != nil || response.Cached {
+			t.Errorf("%s: answered from another variant's entry", variant.name)
+		}
+	}
+	if base.calls != len(variants) {
+		t.Errorf("calls = %d, want %d", base.calls, len(variants))
+	}
+}
@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// Check if a provider error is a rate limit response (HTTP 429).
+// The OpenAI SDK is also used by xAI and OpenAI-compatible providers
+func IsRateLimited(err error) bool {
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return anthropicErr.StatusCode == http.StatusTooManyRequests
+	}
+	var googleErr genai.APIError
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusTooManyRequests
+	}
+	return false
+}
@@ -3,66 +3,89 @@ package ai
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/config"
 	"google.golang.org/genai"
 )
 
 type GoogleProvider struct {
-	client *genai.Client
-	model  string
+	client   *genai.Client
+	model    string
+	params   config.Parameters
+	caps     config.ModelCapabilities
+	settings config.GoogleSettings
 }
 
-func NewGoogleProvider(apiKey, model string) *GoogleProvider {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
-	})
-	if err != nil {
-		// If client creation fails, return a provider with nil client
-		// The error will be caught and displayed by UI when Ask is called
-		return &GoogleProvider{
-			client: nil,
-			model:  model,
-		}
+func NewGoogleProvider(apiKey, model string, opts Options) (*GoogleProvider, error) {
+	clientConfig := &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
 	}
-	return &GoogleProvider{
-		client: client,
-		model:  model,
+	if opts.Google.Vertex {
+		// Vertex AI authenticates with application default credentials.
+		// genai rejects an API key together with a project and location
+		clientConfig.Backend = genai.BackendVertexAI
+		clientConfig.APIKey = ""
+		clientConfig.Project = opts.Google.Project
+		clientConfig.Location = opts.Google.Location
 	}
-}
 
-func (p *GoogleProvider) Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error) {
-	if p.client == nil {
-		return nil, fmt.Errorf("google client not initialized")
+	client, err := genai.NewClient(context.Background(), clientConfig)
+	if err != nil {
+		// Client errors can include the whole config, key and all
+		message := err.Error()
+		if apiKey != "" {
+			message = strings.ReplaceAll(message, apiKey, "<redacted>")
+		}
+		return nil, fmt.Errorf("google client error: %s", message)
 	}
 
-	systemPrompt := BuildSystemPrompt(sysInfo)
-	userPrompt := BuildUserPrompt(question)
+	return &GoogleProvider{
+		client:   client,
+		model:    model,
+		params:   supportedParameters(config.ProviderGoogle, model, opts.Capabilities, opts.Parameters),
+		caps:     opts.Capabilities,
+		settings: opts.Google,
+	}, nil
+}
 
-	// Combine system and user prompts for Gemini
-	fullPrompt := fmt.Sprintf("%s\n\nUser question: %s", systemPrompt, userPrompt)
+func (p *GoogleProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
+	systemPrompt, userPrompt = promptsForModel(p.caps, systemPrompt, userPrompt)
 
-	// Create contents using Text helper
-	contents := genai.Text(fullPrompt)
+	genConfig := p.generateConfig()
+	if systemPrompt != "" {
+		genConfig.SystemInstruction = genai.NewContentFromText(systemPrompt, genai.RoleUser)
+	}
 
-	resp, err := p.client.Models.GenerateContent(ctx, p.model, contents, nil)
+	resp, err := p.client.Models.GenerateContent(ctx, p.model, genai.Text(userPrompt), genConfig)
 	if err != nil {
 		return nil, fmt.Errorf("google API error: %w", err)
 	}
 
 	if len(resp.Candidates) == 0 {
+		// The prompt itself may have been blocked by safety settings
+		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("google blocked the prompt: %s", resp.PromptFeedback.BlockReason)
+		}
 		return nil, fmt.Errorf("no response from Google")
 	}
 
 	candidate := resp.Candidates[0]
 	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+		if candidate.FinishReason == genai.FinishReasonSafety {
+			return nil, fmt.Errorf("google blocked the response due to safety settings")
+		}
 		return nil, fmt.Errorf("no content in Google response")
 	}
 
-	var responseText string
+	// Thought summaries are returned as parts flagged with Thought
+	var responseText, thoughts string
 	for _, part := range candidate.Content.Parts {
-		if part.Text != "" {
+		if part.Thought {
+			thoughts += part.Text
+		} else if part.Text != "" {
 			responseText += part.Text
 		}
 	}
@@ -71,7 +94,66 @@ func (p *GoogleProvider) Ask(ctx context.Context, question string, sysInfo *syst
 		return nil, fmt.Errorf("no text content in Google response")
 	}
 
-	return ParseResponse(responseText), nil
+	response := ParseResponse(responseText)
+	response.Reasoning = strings.TrimSpace(thoughts)
+	if usage := resp.UsageMetadata; usage != nil {
+		response.Usage = Usage{
+			InputTokens:  int64(usage.PromptTokenCount),
+			OutputTokens: int64(usage.CandidatesTokenCount + usage.ThoughtsTokenCount),
+		}
+	}
+	return response, nil
+}
+
+// Build the generation config from parameters and Google settings
+func (p *GoogleProvider) generateConfig() *genai.GenerateContentConfig {
+	genConfig := &genai.GenerateContentConfig{}
+	if p.params.Temperature != nil {
+		genConfig.Temperature = genai.Ptr(float32(*p.params.Temperature))
+	}
+	if p.params.TopP != nil {
+		genConfig.TopP = genai.Ptr(float32(*p.params.TopP))
+	}
+	if p.params.MaxTokens != nil {
+		genConfig.MaxOutputTokens = int32(*p.params.MaxTokens)
+	}
+	if p.params.Seed != nil {
+		genConfig.Seed = genai.Ptr(int32(*p.params.Seed))
+	}
+	switch p.params.Reasoning {
+	case "":
+	case config.ReasoningOff:
+		// gemini-2.5-pro cannot disable thinking, its minimum budget is 128
+		var budget int32
+		if strings.HasPrefix(p.model, "gemini-2.5-pro") {
+			budget = 128
+		}
+		genConfig.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: genai.Ptr(budget)}
+	default:
+		genConfig.ThinkingConfig = &genai.ThinkingConfig{
+			IncludeThoughts: true,
+			ThinkingBudget:  genai.Ptr(int32(thinkingBudgets[p.params.Reasoning])),
+		}
+	}
+
+	// Google-specific generation settings
+	if p.settings.TopK != nil {
+		genConfig.TopK = genai.Ptr(float32(*p.settings.TopK))
+	}
+	if p.settings.PresencePenalty != nil {
+		genConfig.PresencePenalty = genai.Ptr(float32(*p.settings.PresencePenalty))
+	}
+	if p.settings.FrequencyPenalty != nil {
+		genConfig.FrequencyPenalty = genai.Ptr(float32(*p.settings.FrequencyPenalty))
+	}
+	genConfig.StopSequences = p.settings.StopSequences
+	for category, threshold := range p.settings.SafetySettings {
+		genConfig.SafetySettings = append(genConfig.SafetySettings, &genai.SafetySetting{
+			Category:  genai.HarmCategory(category),
+			Threshold: genai.HarmBlockThreshold(threshold),
+		})
+	}
+	return genConfig
 }
 
 func (p *GoogleProvider) GetName() string {
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"github.com/connorgannaway/how/internal/shell"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// An answer with the system it is for, as returned by the HTTP server and MCP
+type AnswerJSON struct {
+	Question string `json:"question"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	ResponseJSON
+	System SystemJSON       `json:"system"`
+	Risk   shell.Assessment `json:"risk"`
+}
+
+// Response fields with JSON names, for --json, the HTTP server and MCP
+type ResponseJSON struct {
+	ID           string            `json:"id,omitempty"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Commands     []string          `json:"commands"`
+	Placeholders []PlaceholderJSON `json:"placeholders,omitempty"`
+	Reasoning    string            `json:"reasoning,omitempty"`
+	RawResponse  string            `json:"raw_response"`
+	Usage        UsageJSON         `json:"usage"`
+	Cached       bool              `json:"cached"`
+}
+
+type PlaceholderJSON struct {
+	Token string `json:"token"`
+	Name  string `json:"name"`
+}
+
+type UsageJSON struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+// System details with JSON names
+type SystemJSON struct {
+	OS             string `json:"os"`
+	OSName         string `json:"os_name"`
+	Shell          string `json:"shell"`
+	PackageManager string `json:"package_manager"`
+}
+
+// Convert an answer to a request for JSON output, assessing the risk of its commands
+func NewAnswerJSON(request *Request, response *Response, provider, model string) AnswerJSON {
+	return AnswerJSON{
+		Question:     request.Question,
+		Provider:     provider,
+		Model:        model,
+		ResponseJSON: response.JSON(),
+		System:       NewSystemJSON(request.SysInfo),
+		Risk:         shell.AnalyzeAll(response.Commands, request.SysInfo.Shell),
+	}
+}
+
+// Convert a response for JSON output
+func (r *Response) JSON() ResponseJSON {
+	output := ResponseJSON{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Commands:    r.Commands,
+		Reasoning:   r.Reasoning,
+		RawResponse: r.RawResponse,
+		Usage:       UsageJSON{InputTokens: r.Usage.InputTokens, OutputTokens: r.Usage.OutputTokens},
+		Cached:      r.Cached,
+	}
+	if output.Commands == nil {
+		output.Commands = []string{}
+	}
+	for _, placeholder := range r.Placeholders {
+		output.Placeholders = append(output.Placeholders, PlaceholderJSON{Token: placeholder.Token, Name: placeholder.Name})
+	}
+	return output
+}
+
+// Convert system details for JSON output
+func NewSystemJSON(sysInfo *system.SystemInfo) SystemJSON {
+	return SystemJSON{
+		OS:             sysInfo.OS,
+		OSName:         sysInfo.OSName,
+		Shell:          sysInfo.Shell,
+		PackageManager: sysInfo.GetPackageManager(),
+	}
+}
@@ -4,35 +4,47 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/config"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
 )
 
 type OpenAIProvider struct {
-	client *openai.Client
-	model  string
+	client             *openai.Client
+	model              string
+	params             config.Parameters
+	caps               config.ModelCapabilities
+	useResponses       bool
+	previousResponseID string
 }
 
-func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
+func NewOpenAIProvider(apiKey, model string, opts Options) *OpenAIProvider {
 	client := openai.NewClient(option.WithAPIKey(apiKey))
 	return &OpenAIProvider{
-		client: &client,
-		model:  model,
+		client:       &client,
+		model:        model,
+		params:       supportedParameters(config.ProviderOpenAI, model, opts.Capabilities, opts.Parameters),
+		caps:         opts.Capabilities,
+		useResponses: opts.UseResponsesAPI,
 	}
 }
 
-func (p *OpenAIProvider) Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error) {
-	systemPrompt := BuildSystemPrompt(sysInfo)
-	userPrompt := BuildUserPrompt(question)
+func (p *OpenAIProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
 
-	chatCompletion, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(systemPrompt),
-			openai.UserMessage(userPrompt),
-		},
-		Model: openai.ChatModel(p.model),
-	})
+	if p.useResponses {
+		systemPrompt, userPrompt = promptsForModel(p.caps, systemPrompt, userPrompt)
+		return p.askResponses(ctx, systemPrompt, userPrompt)
+	}
+
+	request := openai.ChatCompletionNewParams{
+		Messages: chatMessages(p.caps, systemPrompt, userPrompt),
+		Model:    openai.ChatModel(p.model),
+	}
+	applyChatParameters(&request, p.params, false)
+
+	chatCompletion, err := p.client.Chat.Completions.New(ctx, request)
 
 	if err != nil {
 		return nil, fmt.Errorf("OpenAI API error: %w", err)
@@ -42,10 +54,62 @@ func (p *OpenAIProvider) Ask(ctx context.Context, question string, sysInfo *syst
 		return nil, fmt.Errorf("no response from OpenAI")
 	}
 
-	responseText := chatCompletion.Choices[0].Message.Content
-	return ParseResponse(responseText), nil
+	message := chatCompletion.Choices[0].Message
+	response := ParseResponse(message.Content)
+	response.Reasoning = chatReasoning(message)
+	response.Usage = chatUsage(chatCompletion.Usage)
+	return response, nil
 }
 
 func (p *OpenAIProvider) GetName() string {
 	return "OpenAI"
 }
+
+// Continue from a stored response. Requires the Responses API
+func (p *OpenAIProvider) SetPreviousResponseID(id string) error {
+	if !p.useResponses {
+		return fmt.Errorf("follow-ups require the OpenAI Responses API (set \"openai_responses_api\": true)")
+	}
+	p.previousResponseID = id
+	return nil
+}
+
+// Apply generation parameters to a chat completion request.
+// Shared by the OpenAI, xAI and OpenAI-Compatible providers.
+// legacyMaxTokens sends max_tokens instead of max_completion_tokens for servers that predate it.
+func applyChatParameters(request *openai.ChatCompletionNewParams, params config.Parameters, legacyMaxTokens bool) {
+	if params.Temperature != nil {
+		request.Temperature = openai.Float(*params.Temperature)
+	}
+	if params.TopP != nil {
+		request.TopP = openai.Float(*params.TopP)
+	}
+	if params.MaxTokens != nil {
+		if legacyMaxTokens {
+			request.MaxTokens = openai.Int(*params.MaxTokens)
+		} else {
+			request.MaxCompletionTokens = openai.Int(*params.MaxTokens)
+		}
+	}
+	if params.Reasoning != "" {
+		request.ReasoningEffort = shared.ReasoningEffort(params.Reasoning)
+	}
+	if params.Seed != nil {
+		request.Seed = openai.Int(*params.Seed)
+	}
+}
+
+// Build chat messages for the model, using the developer role where preferred
+func chatMessages(caps config.ModelCapabilities, systemPrompt, userPrompt string) []openai.ChatCompletionMessageParamUnion {
+	systemPrompt, userPrompt = promptsForModel(caps, systemPrompt, userPrompt)
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if systemPrompt != "" {
+		if caps.DeveloperRole {
+			messages = append(messages, openai.DeveloperMessage(systemPrompt))
+		} else {
+			messages = append(messages, openai.SystemMessage(systemPrompt))
+		}
+	}
+	return append(messages, openai.UserMessage(userPrompt))
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/config"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 )
@@ -13,35 +13,38 @@ type OpenAICompatibleProvider struct {
 	client  *openai.Client
 	model   string
 	baseURL string
+	params  config.Parameters
+	caps    config.ModelCapabilities
 }
 
-func NewOpenAICompatibleProvider(apiKey, model, baseURL string) *OpenAICompatibleProvider {
-	opts := []option.RequestOption{option.WithBaseURL(baseURL)}
+func NewOpenAICompatibleProvider(apiKey, model, baseURL string, opts Options) *OpenAICompatibleProvider {
+	clientOpts := []option.RequestOption{option.WithBaseURL(baseURL)}
 
 	// Only add API key if provided
 	if apiKey != "" {
-		opts = append(opts, option.WithAPIKey(apiKey))
+		clientOpts = append(clientOpts, option.WithAPIKey(apiKey))
 	}
 
-	client := openai.NewClient(opts...)
+	client := openai.NewClient(clientOpts...)
 	return &OpenAICompatibleProvider{
 		client:  &client,
 		model:   model,
 		baseURL: baseURL,
+		params:  supportedParameters(config.ProviderOpenAICompatible, model, opts.Capabilities, opts.Parameters),
+		caps:    opts.Capabilities,
 	}
 }
 
-func (p *OpenAICompatibleProvider) Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error) {
-	systemPrompt := BuildSystemPrompt(sysInfo)
-	userPrompt := BuildUserPrompt(question)
+func (p *OpenAICompatibleProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
 
-	chatCompletion, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(systemPrompt),
-			openai.UserMessage(userPrompt),
-		},
-		Model: p.model,
-	})
+	request := openai.ChatCompletionNewParams{
+		Messages: chatMessages(p.caps, systemPrompt, userPrompt),
+		Model:    p.model,
+	}
+	applyChatParameters(&request, p.params, true)
+
+	chatCompletion, err := p.client.Chat.Completions.New(ctx, request)
 
 	if err != nil {
 		return nil, fmt.Errorf("OpenAI-compatible API error: %w", err)
@@ -51,8 +54,11 @@ func (p *OpenAICompatibleProvider) Ask(ctx context.Context, question string, sys
 		return nil, fmt.Errorf("no response from OpenAI-compatible API")
 	}
 
-	responseText := chatCompletion.Choices[0].Message.Content
-	return ParseResponse(responseText), nil
+	message := chatCompletion.Choices[0].Message
+	response := ParseResponse(message.Content)
+	response.Reasoning = chatReasoning(message)
+	response.Usage = chatUsage(chatCompletion.Usage)
+	return response, nil
 }
 
 func (p *OpenAICompatibleProvider) GetName() string {
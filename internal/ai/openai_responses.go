@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// Send prompts using the Responses API instead of Chat Completions
+func (p *OpenAIProvider) askResponses(ctx context.Context, systemPrompt, userPrompt string) (*Response, error) {
+	request := responses.ResponseNewParams{
+		Model: shared.ResponsesModel(p.model),
+		Input: responses.ResponseNewParamsInputUnion{
+			OfString: openai.String(userPrompt),
+		},
+		// Stored so the response can be continued with previous_response_id
+		Store: openai.Bool(true),
+	}
+	if systemPrompt != "" {
+		request.Instructions = openai.String(systemPrompt)
+	}
+	if p.previousResponseID != "" {
+		request.PreviousResponseID = openai.String(p.previousResponseID)
+	}
+	if p.params.Temperature != nil {
+		request.Temperature = openai.Float(*p.params.Temperature)
+	}
+	if p.params.TopP != nil {
+		request.TopP = openai.Float(*p.params.TopP)
+	}
+	if p.params.MaxTokens != nil {
+		request.MaxOutputTokens = openai.Int(*p.params.MaxTokens)
+	}
+	if p.params.Reasoning != "" {
+		request.Reasoning = shared.ReasoningParam{
+			Effort:  shared.ReasoningEffort(p.params.Reasoning),
+			Summary: shared.ReasoningSummaryAuto,
+		}
+	}
+
+	resp, err := p.client.Responses.New(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	// Map output items: message text becomes the answer, reasoning summaries the reasoning
+	var responseText, reasoning []string
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "message":
+			for _, content := range item.AsMessage().Content {
+				if content.Type == "output_text" {
+					responseText = append(responseText, content.Text)
+				}
+			}
+		case "reasoning":
+			for _, summary := range item.AsReasoning().Summary {
+				reasoning = append(reasoning, summary.Text)
+			}
+		}
+	}
+
+	if len(responseText) == 0 {
+		return nil, fmt.Errorf("no response from OpenAI")
+	}
+
+	response := ParseResponse(strings.Join(responseText, ""))
+	response.ID = resp.ID
+	response.Reasoning = strings.TrimSpace(strings.Join(reasoning, "\n\n"))
+	response.Usage = Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+	return response, nil
+}
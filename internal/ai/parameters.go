@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/connorgannaway/how/internal/config"
+	"github.com/openai/openai-go/v3"
+)
+
+// Thinking budgets in tokens for Anthropic and Google reasoning levels
+var thinkingBudgets = map[string]int64{
+	config.ReasoningLow:    1024,
+	config.ReasoningMedium: 4096,
+	config.ReasoningHigh:   16384,
+}
+
+// Drop parameters the provider or model does not accept, and map the
+// reasoning level onto the provider's effort values
+func supportedParameters(provider string, model string, caps config.ModelCapabilities, params config.Parameters) config.Parameters {
+	if !caps.Temperature {
+		params.Temperature = nil
+		params.TopP = nil
+	}
+	if !caps.Reasoning {
+		params.Reasoning = ""
+	}
+
+	switch provider {
+	case config.ProviderOpenAI:
+		// Only gpt-5 models can reduce reasoning to "minimal", o-series bottom out at "low"
+		if params.Reasoning == config.ReasoningOff {
+			if strings.HasPrefix(model, "gpt-5") {
+				params.Reasoning = "minimal"
+			} else {
+				params.Reasoning = config.ReasoningLow
+			}
+		}
+	case config.ProviderAnthropic:
+		params.Seed = nil
+		// Newer Claude models reject temperature and top_p together,
+		// and extended thinking rejects both
+		if params.Temperature != nil {
+			params.TopP = nil
+		}
+		if params.Reasoning != "" && params.Reasoning != config.ReasoningOff {
+			params.Temperature = nil
+			params.TopP = nil
+		}
+	case config.ProviderXAI:
+		// xAI only accepts low/high reasoning effort
+		if params.Reasoning == config.ReasoningOff || params.Reasoning == config.ReasoningMedium {
+			params.Reasoning = config.ReasoningLow
+		}
+	case config.ProviderOpenAICompatible:
+		// No common way to disable reasoning, leave it to the server default
+		if params.Reasoning == config.ReasoningOff {
+			params.Reasoning = ""
+		}
+	}
+	return params
+}
+
+// Split prompts for the model, folding the system prompt into the user
+// prompt when the model does not accept one
+func promptsForModel(caps config.ModelCapabilities, systemPrompt, userPrompt string) (string, string) {
+	if caps.SystemPrompt {
+		return systemPrompt, userPrompt
+	}
+	return "", systemPrompt + "\n\n" + userPrompt
+}
+
+// Read reasoning text from a chat completion message.
+// Returned as a non-standard field by xAI and some OpenAI-compatible servers.
+func chatReasoning(message openai.ChatCompletionMessage) string {
+	for _, field := range []string{"reasoning_content", "reasoning"} {
+		// Extra fields are never marked valid, so decode the raw value instead
+		raw, ok := message.JSON.ExtraFields[field]
+		if !ok {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal([]byte(raw.Raw()), &text); err == nil && text != "" {
+			return strings.TrimSpace(text)
+		}
+	}
+	return ""
+}
+
+// Read token counts from a chat completion
+func chatUsage(usage openai.CompletionUsage) Usage {
+	return Usage{InputTokens: usage.PromptTokens, OutputTokens: usage.CompletionTokens}
+}
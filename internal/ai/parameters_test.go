@@ -0,0 +1,208 @@
+package ai
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/config"
+	"github.com/openai/openai-go/v3"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestSupportedParameters(t *testing.T) {
+	all := config.Parameters{Temperature: ptr(0.5), TopP: ptr(0.9), MaxTokens: ptr(int64(500)), Seed: ptr(int64(7))}
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		caps     config.ModelCapabilities
+		params   config.Parameters
+		want     config.Parameters
+	}{
+		{
+			name:     "keeps parameters the model accepts",
+			provider: config.ProviderOpenAI,
+			model:    "gpt-4o",
+			caps:     config.ModelCapabilities{Temperature: true},
+			params:   all,
+			want:     all,
+		},
+		{
+			name:     "drops sampling for models without temperature",
+			provider: config.ProviderOpenAI,
+			model:    "o3",
+			caps:     config.ModelCapabilities{},
+			params:   all,
+			want:     config.Parameters{MaxTokens: ptr(int64(500)), Seed: ptr(int64(7))},
+		},
+		{
+			name:     "anthropic drops seed and top_p with temperature",
+			provider: config.ProviderAnthropic,
+			model:    "claude-sonnet-4-5",
+			caps:     config.ModelCapabilities{Temperature: true},
+			params:   all,
+			want:     config.Parameters{Temperature: ptr(0.5), MaxTokens: ptr(int64(500))},
+		},
+		{
+			name:     "anthropic keeps top_p alone",
+			provider: config.ProviderAnthropic,
+			model:    "claude-sonnet-4-5",
+			caps:     config.ModelCapabilities{Temperature: true},
+			params:   config.Parameters{TopP: ptr(0.9)},
+			want:     config.Parameters{TopP: ptr(0.9)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := supportedParameters(test.provider, test.model, test.caps, test.params)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("supportedParameters() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPromptsForModel(t *testing.T) {
+	tests := []struct {
+		name       string
+		caps       config.ModelCapabilities
+		wantSystem string
+		wantUser   string
+	}{
+		{"system prompt", config.ModelCapabilities{SystemPrompt: true}, "system", "user"},
+		{"folded into user prompt", config.ModelCapabilities{}, "", "system\n\nuser"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			system, user := promptsForModel(test.caps, "system", "user")
+			if system != test.wantSystem || user != test.wantUser {
+				t.Errorf("promptsForModel() = %q, %q, want %q, %q", system, user, test.wantSystem, test.wantUser)
+			}
+		})
+	}
+}
+
+func TestSupportedParametersReasoning(t *testing.T) {
+	reasoning := config.ModelCapabilities{Reasoning: true}
+
+	tests := []struct {
+		name      string
+		provider  string
+		model     string
+		caps      config.ModelCapabilities
+		reasoning string
+		want      string
+	}{
+		{"dropped without capability", config.ProviderOpenAI, "gpt-4o", config.ModelCapabilities{}, config.ReasoningHigh, ""},
+		{"openai gpt-5 off is minimal", config.ProviderOpenAI, "gpt-5", reasoning, config.ReasoningOff, "minimal"},
+		{"openai o-series off is low", config.ProviderOpenAI, "o3", reasoning, config.ReasoningOff, config.ReasoningLow},
+		{"openai keeps medium", config.ProviderOpenAI, "o3", reasoning, config.ReasoningMedium, config.ReasoningMedium},
+		{"xai medium is low", config.ProviderXAI, "grok-3-mini", reasoning, config.ReasoningMedium, config.ReasoningLow},
+		{"xai keeps high", config.ProviderXAI, "grok-3-mini", reasoning, config.ReasoningHigh, config.ReasoningHigh},
+		{"compatible off uses server default", config.ProviderOpenAICompatible, "qwen3", reasoning, config.ReasoningOff, ""},
+		{"anthropic keeps level", config.ProviderAnthropic, "claude-sonnet-4-5", reasoning, config.ReasoningLow, config.ReasoningLow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := supportedParameters(test.provider, test.model, test.caps, config.Parameters{Reasoning: test.reasoning})
+			if got.Reasoning != test.want {
+				t.Errorf("Reasoning = %q, want %q", got.Reasoning, test.want)
+			}
+		})
+	}
+}
+
+func TestSupportedParametersThinkingDropsSampling(t *testing.T) {
+	caps := config.ModelCapabilities{Temperature: true, Reasoning: true}
+	params := config.Parameters{Temperature: ptr(0.5), TopP: ptr(0.9), Reasoning: config.ReasoningMedium}
+
+	got := supportedParameters(config.ProviderAnthropic, "claude-sonnet-4-5", caps, params)
+	if got.Temperature != nil || got.TopP != nil {
+		t.Errorf("got temperature %v and top_p %v, want both dropped with thinking on", got.Temperature, got.TopP)
+	}
+}
+
+func TestAnthropicThinking(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        config.Parameters
+		wantMaxTokens int64
+		wantBudget    int64
+		wantErr       bool
+	}{
+		{
+			name:          "room added without max_tokens",
+			params:        config.Parameters{Reasoning: config.ReasoningMedium},
+			wantMaxTokens: 4096 + defaultAnthropicMaxTokens,
+			wantBudget:    4096,
+		},
+		{
+			name:          "budget kept under max_tokens",
+			params:        config.Parameters{Reasoning: config.ReasoningLow, MaxTokens: ptr(int64(8000))},
+			wantMaxTokens: 8000,
+			wantBudget:    1024,
+		},
+		{
+			name:          "budget lowered to fit max_tokens",
+			params:        config.Parameters{Reasoning: config.ReasoningHigh, MaxTokens: ptr(int64(4096))},
+			wantMaxTokens: 4096,
+			wantBudget:    2048,
+		},
+		{
+			name:          "budget equal to max_tokens is lowered",
+			params:        config.Parameters{Reasoning: config.ReasoningMedium, MaxTokens: ptr(int64(4096))},
+			wantMaxTokens: 4096,
+			wantBudget:    2048,
+		},
+		{
+			name:    "max_tokens too small",
+			params:  config.Parameters{Reasoning: config.ReasoningLow, MaxTokens: ptr(int64(1024))},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			maxTokens, budget, err := anthropicThinking(test.params)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("anthropicThinking() error = %v, want error %v", err, test.wantErr)
+			}
+			if maxTokens != test.wantMaxTokens || budget != test.wantBudget {
+				t.Errorf("anthropicThinking() = %d, %d, want %d, %d", maxTokens, budget, test.wantMaxTokens, test.wantBudget)
+			}
+		})
+	}
+}
+
+func TestChatReasoning(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"reasoning_content", `{"role":"assistant","content":"x","reasoning_content":" thought "}`, "thought"},
+		{"reasoning", `{"role":"assistant","content":"x","reasoning":"thought"}`, "thought"},
+		{"none", `{"role":"assistant","content":"x"}`, ""},
+		{"null", `{"role":"assistant","content":"x","reasoning_content":null}`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var message openai.ChatCompletionMessage
+			if err := json.Unmarshal([]byte(test.body), &message); err != nil {
+				t.Fatal(err)
+			}
+			if got := chatReasoning(message); got != test.want {
+				t.Errorf("chatReasoning() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
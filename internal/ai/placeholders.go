@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A value in a command that the user must fill in, e.g. <PORT>
+type Placeholder struct {
+	Token string // Text as it appears in the command, e.g. "<PORT>"
+	Name  string // Display name, e.g. "PORT"
+}
+
+var (
+	// <PORT>, <branch name>. Requires a letter after "<" so redirections don't match
+	anglePlaceholderPattern = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9_-]*(?: [A-Za-z0-9_-]+)*)>`)
+	// YOUR_BRANCH, YOUR_API_KEY
+	yourPlaceholderPattern = regexp.MustCompile(`\bYOUR_[A-Z0-9_]+\b`)
+	// {filename}, but not ${var}, {} or awk blocks
+	bracePlaceholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// /path/to/dir, /path/to/file.txt, but not /path/together or /src/path/to
+	pathPlaceholderPattern = regexp.MustCompile(`(?:^|[^\w/])(/path/to(?:/[\w.-]+)*)\b`)
+)
+
+// Find placeholders in commands, in order of first appearance
+func FindPlaceholders(commands []string) []Placeholder {
+	var placeholders []Placeholder
+	add := func(token, name string) {
+		if !slices.ContainsFunc(placeholders, func(p Placeholder) bool { return p.Token == token }) {
+			placeholders = append(placeholders, Placeholder{Token: token, Name: name})
+		}
+	}
+
+	for _, command := range commands {
+		type match struct {
+			start       int
+			token, name string
+		}
+		var matches []match
+
+		for _, m := range anglePlaceholderPattern.FindAllStringSubmatchIndex(command, -1) {
+			matches = append(matches, match{m[0], command[m[0]:m[1]], command[m[2]:m[3]]})
+		}
+		for _, m := range yourPlaceholderPattern.FindAllStringIndex(command, -1) {
+			token := command[m[0]:m[1]]
+			matches = append(matches, match{m[0], token, strings.TrimPrefix(token, "YOUR_")})
+		}
+		for _, m := range bracePlaceholderPattern.FindAllStringSubmatchIndex(command, -1) {
+			// Skip shell parameter expansion
+			if m[0] > 0 && command[m[0]-1] == '$' {
+				continue
+			}
+			matches = append(matches, match{m[0], command[m[0]:m[1]], command[m[2]:m[3]]})
+		}
+		for _, m := range pathPlaceholderPattern.FindAllStringSubmatchIndex(command, -1) {
+			token := command[m[2]:m[3]]
+			matches = append(matches, match{m[2], token, token})
+		}
+
+		slices.SortStableFunc(matches, func(a, b match) int { return a.start - b.start })
+		for _, m := range matches {
+			add(m.token, m.name)
+		}
+	}
+	return placeholders
+}
+
+// Replace placeholders in commands with values, keyed by token.
+// Placeholders without a value are left as they are
+func FillPlaceholders(commands []string, values map[string]string) []string {
+	// Replace longer tokens first so /path/to/dir doesn't break /path/to/dir/file
+	tokens := make([]string, 0, len(values))
+	for token := range values {
+		tokens = append(tokens, token)
+	}
+	slices.SortFunc(tokens, func(a, b string) int { return len(b) - len(a) })
+
+	filled := make([]string, len(commands))
+	for i, command := range commands {
+		for _, token := range tokens {
+			if value := values[token]; value != "" {
+				command = strings.ReplaceAll(command, token, value)
+			}
+		}
+		filled[i] = command
+	}
+	return filled
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     []Placeholder
+	}{
+		{"none", []string{"ls -la"}, nil},
+		{"angle", []string{"kill $(lsof -t -i :<PORT>)"}, []Placeholder{{"<PORT>", "PORT"}}},
+		{"angle with spaces", []string{"git checkout <branch name>"}, []Placeholder{{"<branch name>", "branch name"}}},
+		{"redirections", []string{"sort < in.txt > out.txt", "cat <<EOF", "cmd 2>&1 <&3"}, nil},
+		{"your", []string{"git push origin YOUR_BRANCH"}, []Placeholder{{"YOUR_BRANCH", "BRANCH"}}},
+		{"brace", []string{"tar -xzf {archive}"}, []Placeholder{{"{archive}", "archive"}}},
+		{"parameter expansion", []string{"echo ${HOME} {}", "awk '{print $1}'"}, nil},
+		{"path", []string{"du -sh /path/to/dir"}, []Placeholder{{"/path/to/dir", "/path/to/dir"}}},
+		{"bare path", []string{"cd /path/to"}, []Placeholder{{"/path/to", "/path/to"}}},
+		{"path with file", []string{"cat /path/to/file.txt | wc -l"}, []Placeholder{{"/path/to/file.txt", "/path/to/file.txt"}}},
+		{"path in quotes", []string{`ls "/path/to/dir"`}, []Placeholder{{"/path/to/dir", "/path/to/dir"}}},
+		{"path inside another path", []string{"ls /src/path/to/file"}, nil},
+		{"path prefix of a word", []string{"ls /path/together"}, nil},
+		{
+			name:     "order of appearance",
+			commands: []string{"scp /path/to/file YOUR_USER@<HOST>:{dest}"},
+			want:     []Placeholder{{"/path/to/file", "/path/to/file"}, {"YOUR_USER", "USER"}, {"<HOST>", "HOST"}, {"{dest}", "dest"}},
+		},
+		{
+			name:     "duplicates across commands",
+			commands: []string{"echo <NAME>", "touch <NAME> <FILE>"},
+			want:     []Placeholder{{"<NAME>", "NAME"}, {"<FILE>", "FILE"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FindPlaceholders(test.commands)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FindPlaceholders(%q) = %+v, want %+v", test.commands, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		values   map[string]string
+		want     []string
+	}{
+		{
+			name:     "fills every occurrence",
+			commands: []string{"echo <NAME> <NAME>", "touch <NAME>"},
+			values:   map[string]string{"<NAME>": "x"},
+			want:     []string{"echo x x", "touch x"},
+		},
+		{
+			name:     "empty values kept",
+			commands: []string{"git push origin YOUR_BRANCH"},
+			values:   map[string]string{"YOUR_BRANCH": ""},
+			want:     []string{"git push origin YOUR_BRANCH"},
+		},
+		{
+			name:     "longer tokens first",
+			commands: []string{"cp /path/to/dir/file /path/to/dir"},
+			values:   map[string]string{"/path/to/dir": "src", "/path/to/dir/file": "main.go"},
+			want:     []string{"cp main.go src"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FillPlaceholders(test.commands, test.values)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("FillPlaceholders() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -7,9 +7,9 @@ import (
 	"github.com/connorgannaway/how/internal/system"
 )
 
-// Creates a system prompt with OS and shell context
+// Creates a system prompt with OS, shell and optional project context
 func BuildSystemPrompt(sysInfo *system.SystemInfo) string {
-	return fmt.Sprintf(`You are a helpful terminal command assistant. The user is running:
+	prompt := fmt.Sprintf(`You are a helpful terminal command assistant. The user is running:
 OS: %s
 Shell: %s
 Package Manager: %s
@@ -38,16 +38,112 @@ SCRIPT:
 [line 2]
 [line 3]
 
+When a command needs a value the user must supply, write it as an uppercase placeholder
+in angle brackets, e.g. <PORT> or <BRANCH>. Use values from the question instead of
+placeholders whenever they are given.
+
 Be concise and practical. Only include commands that directly answer the question
 for the user's specific OS and shell. Only include a description for complex commands or scripts.
 Do NOT include explanations outside the structured format. Keep it clean and executable.`,
 sysInfo.OSName, sysInfo.Shell, sysInfo.GetPackageManager())
+
+	if sysInfo.Tools != nil {
+		prompt += "\n\nCLI tools on the user's PATH:"
+		if len(sysInfo.Tools.Available) > 0 {
+			prompt += "\nInstalled: " + strings.Join(sysInfo.Tools.Available, ", ")
+		}
+		if len(sysInfo.Tools.Unavailable) > 0 {
+			prompt += "\nNot installed: " + strings.Join(sysInfo.Tools.Unavailable, ", ")
+		}
+		prompt += "\nPrefer installed tools. Avoid tools that are not installed unless no alternative exists, and then include the install command."
+	}
+
+	if sysInfo.Project != nil && !sysInfo.Project.IsEmpty() {
+		prompt += fmt.Sprintf(`
+
+The user is in a project directory:
+%s
+
+When the question relates to this project, prefer its build tools and task names.`,
+			sysInfo.Project.Summary())
+	}
+
+	return prompt
 }
 
-// Creates a user prompt from a question
-func BuildUserPrompt(question string) string {
+// Formats a question for display, prefixed with "how"
+func FormatQuestion(question string) string {
 	if strings.HasPrefix(question, "how ") {
 		return question
 	}
 	return "how " + question
 }
+
+// Creates a user prompt from a question and any attached context
+func BuildUserPrompt(question string, attachments []Attachment) string {
+	prompt := FormatQuestion(question)
+	for _, attachment := range attachments {
+		prompt += "\n\n" + formatAttachment(attachment)
+	}
+	return prompt
+}
+
+// Format an attachment as a labeled, fenced block
+func formatAttachment(attachment Attachment) string {
+	label := "Context from " + attachment.Name
+	if attachment.Truncated {
+		label += " (truncated)"
+	}
+	return label + ":\n" + fence(attachment.Content)
+}
+
+// Wrap content in a code fence longer than any backtick run inside it
+func fence(content string) string {
+	marker := "```"
+	for strings.Contains(content, marker) {
+		marker += "`"
+	}
+	return marker + "\n" + strings.TrimRight(content, "\n") + "\n" + marker
+}
+
+// Format a previous response and its problems as a request for a fix
+func formatCorrection(correction *Correction, shellName string) string {
+	return fmt.Sprintf(`Your previous answer was:
+%s
+
+It is not valid %s syntax:
+- %s
+
+Respond again in the same format with these errors fixed.`,
+		fence(correction.Response), shellName, strings.Join(correction.Problems, "\n- "))
+}
+
+// Creates a user prompt asking what a command does
+func BuildExplainPrompt(command string, attachments []Attachment) string {
+	prompt := fmt.Sprintf(`Explain what this command does:
+%s
+
+Respond in the same format. Use TITLE for a one-line summary of what it does and
+DESCRIPTION for what each part does, including any side effects or risks, on a single line.
+Use COMMAND or SCRIPT for the command itself, corrected if it is not valid for the user's OS and shell.`,
+		fence(command))
+	for _, attachment := range attachments {
+		prompt += "\n\n" + formatAttachment(attachment)
+	}
+	return prompt
+}
+
+// Build the system and user prompts for a request
+func buildPrompts(req *Request) (string, string) {
+	if req.Prompt != nil {
+		return req.Prompt.System, req.Prompt.User
+	}
+	userPrompt := BuildUserPrompt(req.Question, req.Attachments)
+	if req.Explain {
+		userPrompt = BuildExplainPrompt(req.Question, req.Attachments)
+	}
+	if req.Correction != nil {
+		userPrompt += "\n\n" + formatCorrection(req.Correction, req.SysInfo.Shell)
+	}
+	return BuildSystemPrompt(req.SysInfo), userPrompt
+}
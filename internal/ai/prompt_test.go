@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFence(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", "ls -la", "```\nls -la\n```"},
+		{"trailing newlines", "ls\n\n", "```\nls\n```"},
+		{"contains fence", "a ``` b", "````\na ``` b\n````"},
+		{"contains longer fence", "`````", "``````\n`````\n``````"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := fence(test.content); got != test.want {
+				t.Errorf("fence(%q) = %q, want %q", test.content, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment Attachment
+		want       string
+	}{
+		{"stdin", Attachment{Name: "stdin", Content: "error: x\n"}, "Context from stdin:\n```\nerror: x\n```"},
+		{"truncated", Attachment{Name: "log.txt", Content: "x", Truncated: true}, "Context from log.txt (truncated):\n```\nx\n```"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatAttachment(test.attachment); got != test.want {
+				t.Errorf("formatAttachment() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuildExplainPromptFencesCommand(t *testing.T) {
+	prompt := BuildExplainPrompt("echo ```", nil)
+	if !strings.Contains(prompt, "````\necho ```\n````") {
+		t.Errorf("command not fenced safely:\n%s", prompt)
+	}
+}
@@ -5,20 +5,77 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/connorgannaway/how/internal/config"
 	"github.com/connorgannaway/how/internal/system"
 )
 
 type Provider interface {
 	// Sends a question with system context and returns the AI response
-	Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error)
+	Ask(ctx context.Context, req *Request) (*Response, error)
 	GetName() string
 }
 
+// A question with the context it is asked in
+type Request struct {
+	Question    string
+	Attachments []Attachment // Context such as piped input
+	SysInfo     *system.SystemInfo
+	Correction  *Correction // Set when asking the model to fix a previous response
+	Explain     bool        // Question is a command to explain rather than a question
+	Prompt      *Prompt     // Prebuilt prompts sent as is, without a question or system details
+	Refresh     bool        // Skip cached responses and replace them with a new answer
+}
+
+// System and user prompts built by a client, e.g. one calling the gateway
+type Prompt struct {
+	System string
+	User   string
+}
+
+// A previous response sent back to the model with the problems found in it
+type Correction struct {
+	Response string   // Raw text of the previous response
+	Problems []string // e.g. syntax errors
+}
+
+// Context attached to a question
+type Attachment struct {
+	Name      string // Label shown to the model, e.g. "stdin"
+	Content   string
+	Truncated bool
+}
+
+// Implemented by providers that can continue from a previous response
+type ContinuableProvider interface {
+	Provider
+	SetPreviousResponseID(id string) error
+}
+
+// Settings applied when building requests
+type Options struct {
+	Parameters      config.Parameters
+	Capabilities    config.ModelCapabilities
+	UseResponsesAPI bool                  // OpenAI only
+	Google          config.GoogleSettings // Google only
+}
+
 type Response struct {
-	Title       string   // Optional 1-liner title
-	Description string   // Optional description
-	Commands    []string // commands or script lines
-	RawResponse string   // Raw AI response text
+	ID           string        // Provider response ID, if it can be continued from
+	Title        string        // Optional 1-liner title
+	Description  string        // Optional description
+	Commands     []string      // commands or script lines
+	RawResponse  string        // Raw AI response text
+	Reasoning    string        // Optional reasoning summary or thinking text
+	Placeholders []Placeholder // Values in the commands the user must fill in
+	Usage        Usage         // Tokens reported by the provider
+	Cached       bool          `json:"-"` // Answered from the response cache, without a request
+	Edited       bool          `json:"-"` // Commands were changed by the user
+}
+
+// Token counts for a single request
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64 // Includes reasoning tokens
 }
 
 // ParseResponse parses an AI response string into a Response struct
@@ -128,6 +185,8 @@ func ParseResponse(rawResponse string) *Response {
 		response.Commands = []string{rawResponse}
 	}
 
+	response.Placeholders = FindPlaceholders(response.Commands)
+
 	return response
 }
 
@@ -165,18 +224,18 @@ func extractCodeBlocks(text string) []string {
 }
 
 // Create a new provider instance
-func NewProvider(providerName, apiKey, model, baseURL string) (Provider, error) {
+func NewProvider(providerName, apiKey, model, baseURL string, opts Options) (Provider, error) {
 	switch providerName {
 	case "OpenAI":
-		return NewOpenAIProvider(apiKey, model), nil
+		return NewOpenAIProvider(apiKey, model, opts), nil
 	case "Anthropic":
-		return NewAnthropicProvider(apiKey, model), nil
+		return NewAnthropicProvider(apiKey, model, opts), nil
 	case "Google":
-		return NewGoogleProvider(apiKey, model), nil
+		return NewGoogleProvider(apiKey, model, opts)
 	case "xAI":
-		return NewXAIProvider(apiKey, model), nil
+		return NewXAIProvider(apiKey, model, opts), nil
 	case "OpenAI-Compatible":
-		return NewOpenAICompatibleProvider(apiKey, model, baseURL), nil
+		return NewOpenAICompatibleProvider(apiKey, model, baseURL, opts), nil
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", providerName)
 	}
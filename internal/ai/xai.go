@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/config"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 )
@@ -12,27 +12,30 @@ import (
 type XAIProvider struct {
 	client *openai.Client // No golang SDK for xAI, but is OpenAI-compatible
 	model  string
+	params config.Parameters
+	caps   config.ModelCapabilities
 }
 
-func NewXAIProvider(apiKey, model string) *XAIProvider {
+func NewXAIProvider(apiKey, model string, opts Options) *XAIProvider {
 	client := openai.NewClient(option.WithAPIKey(apiKey), option.WithBaseURL("https://api.x.ai/v1"))
 	return &XAIProvider{
 		client: &client,
 		model:  model,
+		params: supportedParameters(config.ProviderXAI, model, opts.Capabilities, opts.Parameters),
+		caps:   opts.Capabilities,
 	}
 }
 
-func (p *XAIProvider) Ask(ctx context.Context, question string, sysInfo *system.SystemInfo) (*Response, error) {
-	systemPrompt := BuildSystemPrompt(sysInfo)
-	userPrompt := BuildUserPrompt(question)
+func (p *XAIProvider) Ask(ctx context.Context, req *Request) (*Response, error) {
+	systemPrompt, userPrompt := buildPrompts(req)
 
-	chatCompletion, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(systemPrompt),
-			openai.UserMessage(userPrompt),
-		},
-		Model: p.model,
-	})
+	request := openai.ChatCompletionNewParams{
+		Messages: chatMessages(p.caps, systemPrompt, userPrompt),
+		Model:    p.model,
+	}
+	applyChatParameters(&request, p.params, false)
+
+	chatCompletion, err := p.client.Chat.Completions.New(ctx, request)
 
 	if err != nil {
 		return nil, fmt.Errorf("xAI API error: %w", err)
@@ -42,8 +45,11 @@ func (p *XAIProvider) Ask(ctx context.Context, question string, sysInfo *system.
 		return nil, fmt.Errorf("no response from xAI")
 	}
 
-	responseText := chatCompletion.Choices[0].Message.Content
-	return ParseResponse(responseText), nil
+	message := chatCompletion.Choices[0].Message
+	response := ParseResponse(message.Content)
+	response.Reasoning = chatReasoning(message)
+	response.Usage = chatUsage(chatCompletion.Usage)
+	return response, nil
 }
 
 func (p *XAIProvider) GetName() string {
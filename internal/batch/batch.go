@@ -0,0 +1,265 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Attempts for a question that keeps hitting provider rate limits
+const maxAttempts = 4
+
+// Wait before the first retry, doubled for each one after. A variable so
+// tests can shorten it
+var retryDelay = 2 * time.Second
+
+// A question to ask, optionally for another OS or shell than this machine's
+type Item struct {
+	Line     int    `json:"-"`
+	ID       string `json:"id,omitempty"`
+	Question string `json:"question"`
+	OS       string `json:"os,omitempty"`    // OS name, e.g. "macOS", "Ubuntu" or "Windows"
+	Shell    string `json:"shell,omitempty"` // e.g. "zsh", "fish" or "powershell"
+}
+
+// Outcome of asking one question, written as a line of JSONL
+type Result struct {
+	Line         int              `json:"line"`
+	ID           string           `json:"id,omitempty"`
+	Question     string           `json:"question"`
+	OS           string           `json:"os"`
+	Shell        string           `json:"shell"`
+	Provider     string           `json:"provider"`
+	Model        string           `json:"model"`
+	Response     *ai.ResponseJSON `json:"response,omitempty"` // Same fields as --json
+	Error        string           `json:"error,omitempty"`
+	LatencyMS    int64            `json:"latency_ms"`
+	Attempts     int              `json:"attempts"`
+	InputTokens  int64            `json:"input_tokens"`
+	OutputTokens int64            `json:"output_tokens"`
+	Cached       bool             `json:"cached"` // Answered from the response cache, so no tokens were used
+}
+
+// Settings for a batch run
+type Options struct {
+	Concurrency       int    // Questions asked at once, at least 1
+	RequestsPerMinute int    // 0 for no limit
+	Model             string // Recorded with each result
+}
+
+// Read questions from a file, or stdin for "-". Lines starting with "{" are
+// JSON items, others are questions. Blank lines and # comments are skipped
+func ReadItems(path string) ([]Item, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open batch file: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var items []Item
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		item := Item{Question: text}
+		if strings.HasPrefix(text, "{") {
+			item = Item{}
+			if err := json.Unmarshal([]byte(text), &item); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if strings.TrimSpace(item.Question) == "" {
+				return nil, fmt.Errorf("line %d: missing question", line)
+			}
+		}
+		item.Line = line
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+	return items, nil
+}
+
+// Ask every question and write the results to w as JSONL, in input order.
+// Progress is written to progress if it is not nil. Returns the number of failed questions
+func Run(ctx context.Context, items []Item, provider ai.Provider, sysInfo *system.SystemInfo, opts Options, w io.Writer, progress io.Writer) (int, error) {
+	concurrency := max(opts.Concurrency, 1)
+	limiter := newLimiter(opts.RequestsPerMinute)
+
+	jobs := make(chan int)
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results <- ask(ctx, items[i], provider, sysInfo, opts.Model, limiter)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range items {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results finish out of order, hold them until the ones before are written
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	lineIndex := make(map[int]int, len(items))
+	for i, item := range items {
+		lineIndex[item.Line] = i
+	}
+	pending := make(map[int]Result)
+	next, done, failed := 0, 0, 0
+	for result := range results {
+		done++
+		if result.Error != "" {
+			failed++
+		}
+		if progress != nil {
+			status := fmt.Sprintf("ok in %.1fs", float64(result.LatencyMS)/1000)
+			if result.Cached {
+				status = "ok from cache"
+			}
+			if result.Error != "" {
+				status = "error: " + result.Error
+			}
+			fmt.Fprintf(progress, "[%d/%d] line %d %s\n", done, len(items), result.Line, status)
+		}
+
+		pending[lineIndex[result.Line]] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			if err := encoder.Encode(result); err != nil {
+				return failed, fmt.Errorf("failed to write results: %w", err)
+			}
+			delete(pending, next)
+			next++
+		}
+	}
+	return failed + len(items) - done, ctx.Err()
+}
+
+// Ask one question, retrying with backoff when the provider is rate limiting
+func ask(ctx context.Context, item Item, provider ai.Provider, base *system.SystemInfo, model string, limiter *limiter) Result {
+	sysInfo := base.ForTarget(item.OS, item.Shell)
+	result := Result{
+		Line:     item.Line,
+		ID:       item.ID,
+		Question: item.Question,
+		OS:       sysInfo.OSName,
+		Shell:    sysInfo.Shell,
+		Provider: provider.GetName(),
+		Model:    model,
+	}
+	request := &ai.Request{
+		Question: item.Question,
+		SysInfo:  sysInfo,
+	}
+
+	var err error
+	for result.Attempts < maxAttempts {
+		if result.Attempts > 0 {
+			if err = sleep(ctx, retryDelay<<(result.Attempts-1)); err != nil {
+				break
+			}
+		}
+		if err = limiter.wait(ctx); err != nil {
+			break
+		}
+
+		result.Attempts++
+		start := time.Now()
+		var response *ai.Response
+		response, err = provider.Ask(ctx, request)
+		result.LatencyMS = time.Since(start).Milliseconds()
+		if err == nil {
+			output := response.JSON()
+			result.Response = &output
+			result.InputTokens = response.Usage.InputTokens
+			result.OutputTokens = response.Usage.OutputTokens
+			result.Cached = response.Cached
+			return result
+		}
+		if !ai.IsRateLimited(err) {
+			break
+		}
+	}
+	result.Error = err.Error()
+	return result
+}
+
+// Spaces requests evenly to stay under a requests per minute limit
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(requestsPerMinute int) *limiter {
+	if requestsPerMinute <= 0 {
+		return &limiter{}
+	}
+	return &limiter{interval: time.Minute / time.Duration(requestsPerMinute)}
+}
+
+// Block until the next request may be sent
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	at := time.Now()
+	if l.next.After(at) {
+		at = l.next
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	return sleep(ctx, time.Until(at))
+}
+
+// Wait for a duration unless the context is cancelled first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
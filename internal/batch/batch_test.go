@@ -0,0 +1,199 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+	"google.golang.org/genai"
+)
+
+// Provider that answers with the question after a delay set per question,
+// failing with a rate limit the first rateLimits times for each question
+type fakeProvider struct {
+	mu         sync.Mutex
+	delays     map[string]time.Duration
+	rateLimits map[string]int
+	errors     map[string]error
+}
+
+func (p *fakeProvider) Ask(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	p.mu.Lock()
+	delay := p.delays[req.Question]
+	limited := p.rateLimits[req.Question] > 0
+	if limited {
+		p.rateLimits[req.Question]--
+	}
+	err := p.errors[req.Question]
+	p.mu.Unlock()
+
+	time.Sleep(delay)
+	if limited {
+		return nil, genai.APIError{Code: 429, Message: "rate limited"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ai.Response{
+		Commands: []string{"echo " + req.Question + " " + req.SysInfo.Shell},
+		Usage:    ai.Usage{InputTokens: 3, OutputTokens: 2},
+	}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "test"
+}
+
+func readResults(t *testing.T, output *bytes.Buffer) []Result {
+	t.Helper()
+	var results []Result
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestReadItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "questions.txt")
+	content := strings.Join([]string{
+		"# comment",
+		"list files",
+		"",
+		`{"id": "mac", "question": "list ports", "os": "macOS", "shell": "zsh"}`,
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := ReadItems(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{Line: 2, Question: "list files"},
+		{Line: 4, ID: "mac", Question: "list ports", OS: "macOS", Shell: "zsh"},
+	}
+	if len(items) != len(want) || items[0] != want[0] || items[1] != want[1] {
+		t.Errorf("ReadItems() = %+v, want %+v", items, want)
+	}
+
+	for _, bad := range []string{`{"question": ""}`, `{not json`} {
+		if err := os.WriteFile(path, []byte("ok\n"+bad), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadItems(path); err == nil || !strings.HasPrefix(err.Error(), "line 2") {
+			t.Errorf("ReadItems(%q) error = %v, want one for line 2", bad, err)
+		}
+	}
+}
+
+func TestRunKeepsInputOrder(t *testing.T) {
+	provider := &fakeProvider{
+		delays: map[string]time.Duration{"slow": 50 * time.Millisecond, "medium": 20 * time.Millisecond},
+		errors: map[string]error{"broken": errors.New("bad request")},
+	}
+	items := []Item{
+		{Line: 1, Question: "slow"},
+		{Line: 2, Question: "medium", Shell: "fish"},
+		{Line: 3, Question: "broken"},
+		{Line: 5, Question: "fast"},
+	}
+	sysInfo := &system.SystemInfo{OS: "linux", OSName: "Ubuntu", Shell: "bash"}
+
+	var output, progress bytes.Buffer
+	failed, err := Run(context.Background(), items, provider, sysInfo, Options{Concurrency: 4, Model: "m"}, &output, &progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+
+	results := readResults(t, &output)
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+	for i, result := range results {
+		if result.Line != items[i].Line {
+			t.Errorf("result %d is for line %d, want %d", i, result.Line, items[i].Line)
+		}
+	}
+	if results[1].Shell != "fish" || results[1].Response.Commands[0] != "echo medium fish" {
+		t.Errorf("target shell not used: %+v", results[1])
+	}
+	if results[2].Error != "bad request" || results[2].Attempts != 1 {
+		t.Errorf("non rate limit error retried or lost: %+v", results[2])
+	}
+	if results[3].InputTokens != 3 || results[3].OutputTokens != 2 || results[3].Model != "m" {
+		t.Errorf("usage or model not recorded: %+v", results[3])
+	}
+	if strings.Count(progress.String(), "\n") != len(items) {
+		t.Errorf("progress = %q, want a line per question", progress.String())
+	}
+}
+
+func TestRunRetriesRateLimits(t *testing.T) {
+	original := retryDelay
+	retryDelay = time.Millisecond
+	t.Cleanup(func() { retryDelay = original })
+
+	provider := &fakeProvider{rateLimits: map[string]int{"busy": 2, "always": maxAttempts}}
+	items := []Item{{Line: 1, Question: "busy"}, {Line: 2, Question: "always"}}
+	sysInfo := &system.SystemInfo{OS: "linux", OSName: "Ubuntu", Shell: "bash"}
+
+	var output bytes.Buffer
+	failed, err := Run(context.Background(), items, provider, sysInfo, Options{Concurrency: 2}, &output, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+
+	results := readResults(t, &output)
+	if results[0].Error != "" || results[0].Attempts != 3 {
+		t.Errorf("busy = %+v, want success on the third attempt", results[0])
+	}
+	if results[1].Error == "" || results[1].Attempts != maxAttempts {
+		t.Errorf("always = %+v, want failure after %d attempts", results[1], maxAttempts)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := newLimiter(6000) // One request every 10ms
+	start := time.Now()
+	for range 4 {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 30ms", elapsed)
+	}
+
+	// The second request would wait a minute
+	slow := newLimiter(1)
+	if err := slow.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := slow.wait(ctx); err == nil {
+		t.Error("wait() ignored a cancelled context")
+	}
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Age after which a temp file is assumed to be left by a crashed write
+const staleTemp = time.Minute
+
+// On-disk key-value store with a time to live and a size limit.
+// Each entry is a file named by its key
+type Cache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+}
+
+// Summary of the entries in a cache
+type Stats struct {
+	Entries int
+	Bytes   int64
+	Expired int
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// Create a cache in dir, creating the directory if needed
+func New(dir string, ttl time.Duration, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, ttl: ttl, maxBytes: maxBytes}, nil
+}
+
+// Hash values into a cache key
+func Key(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Read an entry. Expired entries are removed and reported as missing
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path)
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Write an entry, then evict the oldest entries past the size limit
+func (c *Cache) Put(key string, data []byte) error {
+	// Write to a temp file first so readers never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return c.evict()
+}
+
+// Remove all entries and temp files, returning how many entries were removed
+func (c *Cache) Clear() (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+	c.removeTemp(0)
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return len(entries), nil
+}
+
+// Summarize the entries in the cache
+func (c *Cache) Stats() (Stats, error) {
+	var stats Stats
+	entries, err := c.entries()
+	if err != nil {
+		return stats, err
+	}
+	for _, entry := range entries {
+		stats.Entries++
+		stats.Bytes += entry.Size()
+		modTime := entry.ModTime()
+		if time.Since(modTime) > c.ttl {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || modTime.Before(stats.Oldest) {
+			stats.Oldest = modTime
+		}
+		if modTime.After(stats.Newest) {
+			stats.Newest = modTime
+		}
+	}
+	return stats, nil
+}
+
+// Remove expired entries, then the oldest entries until under the size limit
+func (c *Cache) evict() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	c.removeTemp(staleTemp)
+	slices.SortFunc(entries, func(a, b os.FileInfo) int { return a.ModTime().Compare(b.ModTime()) })
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size()
+	}
+	for _, entry := range entries {
+		if total <= c.maxBytes && time.Since(entry.ModTime()) <= c.ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err == nil {
+			total -= entry.Size()
+		}
+	}
+	return nil
+}
+
+// Remove temp files older than maxAge, left behind by interrupted writes
+func (c *Cache) removeTemp(maxAge time.Duration) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "tmp-*"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) >= maxAge {
+			os.Remove(path)
+		}
+	}
+}
+
+// List the entry files in the cache directory
+func (c *Cache) entries() ([]os.FileInfo, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	var entries []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, info)
+	}
+	return entries, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
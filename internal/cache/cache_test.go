@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCache(t *testing.T, ttl time.Duration, maxBytes int64) *Cache {
+	t.Helper()
+	c, err := New(t.TempDir(), ttl, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// Backdate an entry's modification time
+func age(t *testing.T, path string, by time.Duration) {
+	t.Helper()
+	old := time.Now().Add(-by)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	a, err := Key("prompt", "openai", map[string]bool{"x": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Key("prompt", "openai", map[string]bool{"x": true})
+	c, _ := Key("prompt", "openai", map[string]bool{"x": false})
+	if a != b {
+		t.Error("equal values gave different keys")
+	}
+	if a == c {
+		t.Error("different values gave the same key")
+	}
+	if _, err := Key(func() {}); err == nil {
+		t.Error("Key() of an unencodable value returned no error")
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	c := newCache(t, time.Hour, 1<<20)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get() of a missing key succeeded")
+	}
+	if err := c.Put("key", []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("key", []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := c.Get("key")
+	if !ok || string(data) != "two" {
+		t.Errorf("Get() = %q, %v, want the replaced entry", data, ok)
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	c := newCache(t, time.Hour, 1<<20)
+	if err := c.Put("key", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	age(t, c.path("key"), 2*time.Hour)
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("Get() returned an expired entry")
+	}
+	if _, err := os.Stat(c.path("key")); !os.IsNotExist(err) {
+		t.Error("expired entry not removed")
+	}
+}
+
+func TestEvict(t *testing.T) {
+	c := newCache(t, time.Hour, 10)
+
+	// Oldest entries go first once over the size limit
+	for i, key := range []string{"a", "b", "c"} {
+		if err := c.Put(key, []byte("1234")); err != nil {
+			t.Fatal(err)
+		}
+		age(t, c.path(key), time.Duration(3-i)*time.Minute)
+	}
+	if err := c.evict(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("oldest entry kept over the size limit")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("entry %s evicted under the size limit", key)
+		}
+	}
+
+	// Expired entries go even under the size limit
+	age(t, c.path("b"), 2*time.Hour)
+	if err := c.evict(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("b")); !os.IsNotExist(err) {
+		t.Error("expired entry kept")
+	}
+}
+
+func TestEvictRemovesStaleTemp(t *testing.T) {
+	c := newCache(t, time.Hour, 1<<20)
+	stale := filepath.Join(c.dir, "tmp-stale")
+	fresh := filepath.Join(c.dir, "tmp-fresh")
+	for _, path := range []string{stale, fresh} {
+		if err := os.WriteFile(path, []byte("partial"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	age(t, stale, 2*staleTemp)
+
+	if err := c.evict(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temp file kept")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("temp file of a write in progress removed")
+	}
+}
+
+func TestClearAndStats(t *testing.T) {
+	c := newCache(t, time.Hour, 1<<20)
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, "tmp-left"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 || stats.Bytes != 8 || stats.Expired != 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Clear() removed %d, want 2", removed)
+	}
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	if len(names) != 0 {
+		t.Errorf("files left after Clear(): %s", strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default response cache limits
+const (
+	DefaultCacheTTLHours  = 7 * 24
+	DefaultCacheMaxSizeMB = 50
+)
+
+// Settings for the on-disk response cache
+type CacheSettings struct {
+	Disabled  bool `json:"disabled,omitempty"`
+	TTLHours  int  `json:"ttl_hours,omitempty"`   // How long responses are reused
+	MaxSizeMB int  `json:"max_size_mb,omitempty"` // Oldest responses are evicted past this size
+}
+
+// Check limits are not negative
+func (c CacheSettings) Validate() error {
+	if c.TTLHours < 0 {
+		return fmt.Errorf("ttl_hours must not be negative")
+	}
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("max_size_mb must not be negative")
+	}
+	return nil
+}
+
+// Get how long responses are reused, falling back to the default
+func (c CacheSettings) GetTTL() time.Duration {
+	if c.TTLHours > 0 {
+		return time.Duration(c.TTLHours) * time.Hour
+	}
+	return DefaultCacheTTLHours * time.Hour
+}
+
+// Get the cache size limit in bytes, falling back to the default
+func (c CacheSettings) GetMaxBytes() int64 {
+	if c.MaxSizeMB > 0 {
+		return int64(c.MaxSizeMB) * 1024 * 1024
+	}
+	return DefaultCacheMaxSizeMB * 1024 * 1024
+}
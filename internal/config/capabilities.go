@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Features a model supports, used to adapt requests per model
+type ModelCapabilities struct {
+	SystemPrompt  bool `json:"system_prompt"`  // Accepts a system prompt
+	DeveloperRole bool `json:"developer_role"` // Prefers the developer role over system (OpenAI)
+	Temperature   bool `json:"temperature"`    // Accepts temperature and top_p
+	Reasoning     bool `json:"reasoning"`      // Accepts a reasoning effort or thinking budget
+	JSONMode      bool `json:"json_mode"`      // Supports a JSON response format
+	Vision        bool `json:"vision"`         // Accepts image input
+	ContextWindow int  `json:"context_window"` // In tokens, 0 if unknown
+}
+
+// Capabilities for each model in ProviderModels
+var modelCapabilities = map[string]ModelCapabilities{
+	// OpenAI GPT-5 Models
+	"gpt-5":      {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 400000},
+	"gpt-5-mini": {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 400000},
+	"gpt-5-nano": {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 400000},
+	// OpenAI O Models
+	"o4-mini": {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 200000},
+	"o3":      {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 200000},
+	"o3-mini": {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, ContextWindow: 200000},
+	"o1":      {SystemPrompt: true, DeveloperRole: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 200000},
+	"o1-mini": {ContextWindow: 128000}, // No system/developer messages or reasoning effort
+	// OpenAI GPT-4 Models
+	"gpt-4.1":       {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 1047576},
+	"gpt-4.1-mini":  {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 1047576},
+	"gpt-4.1-nano":  {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 1047576},
+	"gpt-4o":        {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 128000},
+	"gpt-4o-mini":   {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 128000},
+	"gpt-4-turbo":   {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 128000},
+	"gpt-4":         {SystemPrompt: true, Temperature: true, ContextWindow: 8192},
+	"gpt-3.5-turbo": {SystemPrompt: true, Temperature: true, JSONMode: true, ContextWindow: 16385},
+
+	// Anthropic
+	"claude-opus-4-1":          {SystemPrompt: true, Temperature: true, Reasoning: true, Vision: true, ContextWindow: 200000},
+	"claude-opus-4-0":          {SystemPrompt: true, Temperature: true, Reasoning: true, Vision: true, ContextWindow: 200000},
+	"claude-sonnet-4-5":        {SystemPrompt: true, Temperature: true, Reasoning: true, Vision: true, ContextWindow: 200000},
+	"claude-sonnet-4-0":        {SystemPrompt: true, Temperature: true, Reasoning: true, Vision: true, ContextWindow: 200000},
+	"claude-3-7-sonnet-latest": {SystemPrompt: true, Temperature: true, Reasoning: true, Vision: true, ContextWindow: 200000},
+	"claude-3-5-haiku-latest":  {SystemPrompt: true, Temperature: true, Vision: true, ContextWindow: 200000},
+
+	// Google
+	"gemini-2.5-pro":        {SystemPrompt: true, Temperature: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 1048576},
+	"gemini-2.5-flash":      {SystemPrompt: true, Temperature: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 1048576},
+	"gemini-2.5-flash-lite": {SystemPrompt: true, Temperature: true, Reasoning: true, JSONMode: true, Vision: true, ContextWindow: 1048576},
+	"gemini-2.0-flash":      {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 1048576},
+	"gemini-2.0-flash-lite": {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 1048576},
+
+	// xAI. Grok 4 and grok-code reason but do not accept reasoning_effort
+	"grok-code-fast-1":          {SystemPrompt: true, Temperature: true, JSONMode: true, ContextWindow: 256000},
+	"grok-4-fast-reasoning":     {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 2000000},
+	"grok-4-fast-non-reasoning": {SystemPrompt: true, Temperature: true, JSONMode: true, Vision: true, ContextWindow: 2000000},
+	"grok-3-mini":               {SystemPrompt: true, Temperature: true, Reasoning: true, JSONMode: true, ContextWindow: 131072},
+	"grok-3":                    {SystemPrompt: true, Temperature: true, JSONMode: true, ContextWindow: 131072},
+}
+
+// Capabilities assumed for models not in the table
+var defaultCapabilities = ModelCapabilities{
+	SystemPrompt: true,
+	Temperature:  true,
+}
+
+// Look up built-in capabilities for a model.
+// Dated or suffixed model names (e.g. "gpt-4o-2024-08-06") match their longest known prefix.
+func LookupModelCapabilities(model string) ModelCapabilities {
+	if caps, ok := modelCapabilities[model]; ok {
+		return caps
+	}
+
+	var match string
+	for name := range modelCapabilities {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(match) {
+			match = name
+		}
+	}
+	if match != "" {
+		return modelCapabilities[match]
+	}
+
+	return defaultCapabilities
+}
+
+// Resolve capabilities for a model, preferring entries in the config's model_capabilities
+func (c *Config) ResolveCapabilities(model string) ModelCapabilities {
+	if caps, ok := c.ModelCapabilities[model]; ok {
+		return caps
+	}
+	return LookupModelCapabilities(model)
+}
+
+// Short summary of notable capabilities for display
+func (m ModelCapabilities) Summary() string {
+	var parts []string
+	if m.ContextWindow > 0 {
+		parts = append(parts, formatContextWindow(m.ContextWindow)+" context")
+	}
+	if m.Reasoning {
+		parts = append(parts, "reasoning")
+	}
+	if m.Vision {
+		parts = append(parts, "vision")
+	}
+	if m.JSONMode {
+		parts = append(parts, "JSON")
+	}
+	if !m.Temperature {
+		parts = append(parts, "no temperature")
+	}
+	if !m.SystemPrompt {
+		parts = append(parts, "no system prompt")
+	}
+	return strings.Join(parts, " • ")
+}
+
+// Format a token count as e.g. "128k" or "1M"
+func formatContextWindow(tokens int) string {
+	if tokens >= 1000000 {
+		return fmt.Sprintf("%dM", (tokens+500000)/1000000)
+	}
+	return fmt.Sprintf("%dk", (tokens+500)/1000)
+}
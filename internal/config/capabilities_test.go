@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestLookupModelCapabilities(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelCapabilities
+	}{
+		{"gpt-5", modelCapabilities["gpt-5"]},
+		{"gpt-5-mini", modelCapabilities["gpt-5-mini"]},
+		{"gpt-5-2025-08-07", modelCapabilities["gpt-5"]},
+		{"gpt-5-mini-2025-08-07", modelCapabilities["gpt-5-mini"]},
+		{"o3", modelCapabilities["o3"]},
+		{"o3-mini-high", modelCapabilities["o3-mini"]},
+		{"llama3.1:8b", defaultCapabilities},
+		{"gpt-5x", defaultCapabilities},
+	}
+
+	for _, test := range tests {
+		t.Run(test.model, func(t *testing.T) {
+			if got := LookupModelCapabilities(test.model); got != test.want {
+				t.Errorf("LookupModelCapabilities(%q) = %+v, want %+v", test.model, got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveCapabilities(t *testing.T) {
+	override := ModelCapabilities{SystemPrompt: true, ContextWindow: 8192}
+	config := &Config{ModelCapabilities: map[string]ModelCapabilities{"local-model": override}}
+
+	if got := config.ResolveCapabilities("local-model"); got != override {
+		t.Errorf("ResolveCapabilities(local-model) = %+v, want %+v", got, override)
+	}
+	if got := config.ResolveCapabilities("gpt-5"); got != modelCapabilities["gpt-5"] {
+		t.Errorf("ResolveCapabilities(gpt-5) = %+v, want the built-in entry", got)
+	}
+}
@@ -9,10 +9,45 @@ const (
 	ProviderOpenAICompatible = "OpenAI-Compatible"
 )
 
+// Default size limit for piped input or a file attached to a question
+const DefaultMaxContextBytes = 32 * 1024
+
 type Config struct {
 	CurrentProvider string `json:"current_provider"`
 	CurrentModel    string `json:"current_model"`
 	BaseURL         string `json:"base_url,omitempty"` // For OpenAI-Compatible providers
+
+	// Use the OpenAI Responses API instead of Chat Completions
+	OpenAIResponsesAPI bool `json:"openai_responses_api,omitempty"`
+
+	// Vertex AI, safety and generation settings for Google
+	Google GoogleSettings `json:"google,omitzero"`
+
+	// Generation parameters. Provider and model entries override the global block
+	Parameters         Parameters            `json:"parameters,omitzero"`
+	ProviderParameters map[string]Parameters `json:"provider_parameters,omitempty"` // Keyed by provider name
+	ModelParameters    map[string]Parameters `json:"model_parameters,omitempty"`    // Keyed by model name
+
+	// Show the model's reasoning summary, when returned, below the answer
+	ShowReasoning bool `json:"show_reasoning,omitempty"`
+
+	// CLI tools to report as installed or not, defaults to system.DefaultTools
+	Tools []string `json:"tools,omitempty"`
+
+	// Describe the project in the working directory (languages, build tools, tasks) to the model
+	ProjectContext bool `json:"project_context,omitempty"`
+
+	// Size limit in bytes for piped input and each attached file, larger input is truncated
+	MaxContextBytes int `json:"max_context_bytes,omitempty"`
+
+	// Overrides the built-in capability table, e.g. for local models without system prompt support
+	ModelCapabilities map[string]ModelCapabilities `json:"model_capabilities,omitempty"` // Keyed by model name
+
+	// Stop recording questions and answers in the local history
+	DisableHistory bool `json:"disable_history,omitempty"`
+
+	// Reuse responses to identical requests from an on-disk cache
+	Cache CacheSettings `json:"cache,omitzero"`
 }
 
 // List of available models for each provider
@@ -91,7 +126,17 @@ func (c *Config) IsConfigured() (bool, []string) {
 		missing = append(missing, "model")
 		ready = false
 	}
-	if c.CurrentProvider != ProviderOpenAICompatible {
+	if c.CurrentProvider == ProviderGoogle && c.Google.Vertex {
+		// Vertex AI uses application default credentials, not an API key
+		if c.Google.Project == "" {
+			missing = append(missing, "Vertex AI project")
+			ready = false
+		}
+		if c.Google.Location == "" {
+			missing = append(missing, "Vertex AI location")
+			ready = false
+		}
+	} else if c.CurrentProvider != ProviderOpenAICompatible {
 		// Check keyring for API key
 		hasKey, err := HasAPIKeyInKeyring(c.CurrentProvider)
 		if err != nil || !hasKey {
@@ -112,3 +157,11 @@ func (c *Config) SetProvider(provider, model string) {
 	c.CurrentProvider = provider
 	c.CurrentModel = model
 }
+
+// Get the size limit for attached context, falling back to the default
+func (c *Config) GetMaxContextBytes() int {
+	if c.MaxContextBytes > 0 {
+		return c.MaxContextBytes
+	}
+	return DefaultMaxContextBytes
+}
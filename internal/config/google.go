@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Settings specific to the Google provider
+type GoogleSettings struct {
+	// Use Vertex AI instead of the Gemini API. Authenticates with
+	// application default credentials; any stored API key is not used
+	Vertex   bool   `json:"vertex,omitempty"`
+	Project  string `json:"project,omitempty"`
+	Location string `json:"location,omitempty"`
+
+	// Harm category to block threshold, e.g. "HARM_CATEGORY_DANGEROUS_CONTENT": "BLOCK_ONLY_HIGH"
+	SafetySettings map[string]string `json:"safety_settings,omitempty"`
+
+	// Generation config beyond the shared parameters
+	TopK             *float64 `json:"top_k,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	StopSequences    []string `json:"stop_sequences,omitempty"`
+}
+
+// Harm categories accepted in safety_settings
+var googleHarmCategories = []string{
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+	"HARM_CATEGORY_HARASSMENT",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_CIVIC_INTEGRITY",
+}
+
+// Block thresholds accepted in safety_settings
+var googleBlockThresholds = []string{
+	"BLOCK_LOW_AND_ABOVE",
+	"BLOCK_MEDIUM_AND_ABOVE",
+	"BLOCK_ONLY_HIGH",
+	"BLOCK_NONE",
+	"OFF",
+}
+
+// Check Google settings are usable
+func (g GoogleSettings) Validate() error {
+	for category, threshold := range g.SafetySettings {
+		if !slices.Contains(googleHarmCategories, category) {
+			return fmt.Errorf("invalid harm category: %s", category)
+		}
+		if !slices.Contains(googleBlockThresholds, threshold) {
+			return fmt.Errorf("invalid block threshold for %s: %s", category, threshold)
+		}
+	}
+	if g.TopK != nil && *g.TopK <= 0 {
+		return fmt.Errorf("top_k must be greater than 0")
+	}
+	return nil
+}
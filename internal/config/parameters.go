@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Reasoning levels
+const (
+	ReasoningOff    = "off"
+	ReasoningLow    = "low"
+	ReasoningMedium = "medium"
+	ReasoningHigh   = "high"
+)
+
+// Generation parameters sent with each request.
+// Nil/empty fields are left to the provider's defaults.
+type Parameters struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int64   `json:"max_tokens,omitempty"`
+	Reasoning   string   `json:"reasoning,omitempty"` // "off", "low", "medium", "high"
+	Seed        *int64   `json:"seed,omitempty"`
+}
+
+// Return a copy of p with any fields set in override replacing p's values
+func (p Parameters) Merge(override Parameters) Parameters {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.MaxTokens != nil {
+		p.MaxTokens = override.MaxTokens
+	}
+	if override.Reasoning != "" {
+		p.Reasoning = override.Reasoning
+	}
+	if override.Seed != nil {
+		p.Seed = override.Seed
+	}
+	return p
+}
+
+// Check parameter values are within accepted ranges
+func (p Parameters) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1")
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be greater than 0")
+	}
+	if p.Reasoning != "" && !slices.Contains(GetReasoningLevels(), p.Reasoning) {
+		return fmt.Errorf("invalid reasoning level: %s", p.Reasoning)
+	}
+	return nil
+}
+
+// Check if no parameters are set
+func (p Parameters) IsEmpty() bool {
+	return p == Parameters{}
+}
+
+// Format set parameters as "name=value" pairs
+func (p Parameters) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, "temperature="+strconv.FormatFloat(*p.Temperature, 'g', -1, 64))
+	}
+	if p.TopP != nil {
+		parts = append(parts, "top_p="+strconv.FormatFloat(*p.TopP, 'g', -1, 64))
+	}
+	if p.MaxTokens != nil {
+		parts = append(parts, "max_tokens="+strconv.FormatInt(*p.MaxTokens, 10))
+	}
+	if p.Reasoning != "" {
+		parts = append(parts, "reasoning="+p.Reasoning)
+	}
+	if p.Seed != nil {
+		parts = append(parts, "seed="+strconv.FormatInt(*p.Seed, 10))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Return a list of accepted reasoning levels
+func GetReasoningLevels() []string {
+	return []string{ReasoningOff, ReasoningLow, ReasoningMedium, ReasoningHigh}
+}
+
+// Resolve parameters for a provider and model.
+// Model parameters override provider parameters, which override global parameters.
+func (c *Config) ResolveParameters(provider, model string) Parameters {
+	params := c.Parameters
+	if providerParams, ok := c.ProviderParameters[provider]; ok {
+		params = params.Merge(providerParams)
+	}
+	if modelParams, ok := c.ModelParameters[model]; ok {
+		params = params.Merge(modelParams)
+	}
+	return params
+}
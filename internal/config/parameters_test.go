@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestParametersValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Parameters
+		wantErr bool
+	}{
+		{"empty", Parameters{}, false},
+		{"all valid", Parameters{Temperature: ptr(2.0), TopP: ptr(1.0), MaxTokens: ptr(int64(1)), Reasoning: ReasoningHigh}, false},
+		{"zero temperature", Parameters{Temperature: ptr(0.0)}, false},
+		{"negative temperature", Parameters{Temperature: ptr(-1.0)}, true},
+		{"temperature above 2", Parameters{Temperature: ptr(2.1)}, true},
+		{"zero top_p", Parameters{TopP: ptr(0.0)}, true},
+		{"top_p above 1", Parameters{TopP: ptr(1.5)}, true},
+		{"zero max_tokens", Parameters{MaxTokens: ptr(int64(0))}, true},
+		{"negative max_tokens", Parameters{MaxTokens: ptr(int64(-5))}, true},
+		{"unknown reasoning", Parameters{Reasoning: "extreme"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.params.Validate()
+			if (err != nil) != test.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveParameters(t *testing.T) {
+	config := &Config{
+		Parameters: Parameters{Temperature: ptr(0.2), MaxTokens: ptr(int64(100))},
+		ProviderParameters: map[string]Parameters{
+			ProviderAnthropic: {MaxTokens: ptr(int64(2048))},
+		},
+		ModelParameters: map[string]Parameters{
+			"claude-sonnet-4-5": {Temperature: ptr(1.0)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		want     Parameters
+	}{
+		{"global only", ProviderOpenAI, "gpt-4o", Parameters{Temperature: ptr(0.2), MaxTokens: ptr(int64(100))}},
+		{"provider overrides global", ProviderAnthropic, "claude-opus-4-1", Parameters{Temperature: ptr(0.2), MaxTokens: ptr(int64(2048))}},
+		{"model overrides provider", ProviderAnthropic, "claude-sonnet-4-5", Parameters{Temperature: ptr(1.0), MaxTokens: ptr(int64(2048))}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := config.ResolveParameters(test.provider, test.model)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ResolveParameters() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
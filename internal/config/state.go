@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// State persisted between runs, stored next to the config file
+type State struct {
+	LastResponseID string `json:"last_response_id,omitempty"` // For follow-ups with the OpenAI Responses API
+}
+
+// Get the path to the state file
+func GetStatePath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "state.json"), nil
+}
+
+// Load state from disk
+func LoadState() (*State, error) {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	// If state doesn't exist, return empty state
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save state to disk
+func SaveState(state *State) error {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(statePath, data, 0600)
+}
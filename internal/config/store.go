@@ -49,6 +49,61 @@ func GetConfigPath() (string, error) {
         return filepath.Join(howDir, "config.json"), nil
   }
 
+// Get the directory for cached data, creating it if needed.
+// Uses XDG_CACHE_HOME if set, otherwise ~/.cache (or %LocalAppData% on Windows)
+func GetCacheDir() (string, error) {
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		if runtime.GOOS == "windows" {
+			var err error
+			cacheDir, err = os.UserCacheDir()
+			if err != nil {
+				return "", err
+			}
+		} else {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			cacheDir = filepath.Join(homeDir, ".cache")
+		}
+	}
+
+	howDir := filepath.Join(cacheDir, "how")
+	if err := os.MkdirAll(howDir, 0700); err != nil {
+		return "", err
+	}
+	return howDir, nil
+}
+
+// Get the directory for persistent data such as history, creating it if needed.
+// Uses XDG_DATA_HOME if set, otherwise ~/.local/share (or %AppData% on Windows,
+// since %LocalAppData% may be cleared by cache cleanups)
+func GetDataDir() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		if runtime.GOOS == "windows" {
+			var err error
+			dataDir, err = os.UserConfigDir()
+			if err != nil {
+				return "", err
+			}
+		} else {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dataDir = filepath.Join(homeDir, ".local", "share")
+		}
+	}
+
+	howDir := filepath.Join(dataDir, "how")
+	if err := os.MkdirAll(howDir, 0700); err != nil {
+		return "", err
+	}
+	return howDir, nil
+}
+
 // Load the configuration from disk
 func Load() (*Config, error) {
 	configPath, err := GetConfigPath()
@@ -80,6 +135,31 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Validate generation parameters
+	if err := config.Parameters.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	for provider, params := range config.ProviderParameters {
+		if err := params.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid parameters for %s: %w", provider, err)
+		}
+	}
+	for model, params := range config.ModelParameters {
+		if err := params.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid parameters for %s: %w", model, err)
+		}
+	}
+
+	// Validate Google settings
+	if err := config.Google.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid google settings: %w", err)
+	}
+
+	// Validate cache settings
+	if err := config.Cache.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cache settings: %w", err)
+	}
+
 	return &config, nil
 }
 
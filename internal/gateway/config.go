@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/connorgannaway/how/internal/config"
+)
+
+// Prefix of user tokens, so they are easy to recognize
+const tokenPrefix = "how_"
+
+// Gateway users and the models they can reach
+type Config struct {
+	// Models served, mapped to the provider that answers them. Defaults to the
+	// models of every provider with an API key, plus the current model
+	Models map[string]string `json:"models,omitempty"`
+	Users  []User            `json:"users"`
+}
+
+// A gateway user. Only a hash of the token is stored
+type User struct {
+	Name          string    `json:"name"`
+	TokenHash     string    `json:"token_hash"`
+	Models        []string  `json:"models,omitempty"`         // Allowed models, empty for all
+	DailyRequests int       `json:"daily_requests,omitempty"` // Requests per UTC day, 0 for no limit
+	MonthlyTokens int64     `json:"monthly_tokens,omitempty"` // Input and output tokens per UTC month, 0 for no limit
+	Created       time.Time `json:"created"`
+}
+
+// Check if the user may use a model
+func (u *User) Allows(model string) bool {
+	return len(u.Models) == 0 || slices.Contains(u.Models, model)
+}
+
+// Get the path to the gateway config, next to config.json
+func GetConfigPath() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "gateway.json"), nil
+}
+
+// Get the path to the usage log
+func GetUsagePath() (string, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "gateway-usage.jsonl"), nil
+}
+
+// Load the gateway config, empty if it doesn't exist yet
+func LoadConfig() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var gatewayConfig Config
+	if err := json.Unmarshal(data, &gatewayConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse gateway config: %w", err)
+	}
+	for model, provider := range gatewayConfig.Models {
+		if !slices.Contains(config.GetProviders(), provider) {
+			return nil, fmt.Errorf("invalid provider for %s: %s", model, provider)
+		}
+	}
+	return &gatewayConfig, nil
+}
+
+// Save the gateway config
+func SaveConfig(gatewayConfig *Config) error {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(gatewayConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, data, 0600)
+}
+
+// Find a user by name
+func (c *Config) FindUser(name string) *User {
+	for i := range c.Users {
+		if c.Users[i].Name == name {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+// Add a user and return their token, which is not stored
+func (c *Config) AddUser(name string, models []string, dailyRequests int, monthlyTokens int64) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("missing user name")
+	}
+	if c.FindUser(name) != nil {
+		return "", fmt.Errorf("user %s already exists", name)
+	}
+	if dailyRequests < 0 || monthlyTokens < 0 {
+		return "", fmt.Errorf("quotas must not be negative")
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := tokenPrefix + hex.EncodeToString(secret)
+
+	c.Users = append(c.Users, User{
+		Name:          name,
+		TokenHash:     HashToken(token),
+		Models:        models,
+		DailyRequests: dailyRequests,
+		MonthlyTokens: monthlyTokens,
+		Created:       time.Now(),
+	})
+	return token, nil
+}
+
+// Remove a user by name
+func (c *Config) RemoveUser(name string) error {
+	for i, user := range c.Users {
+		if user.Name == name {
+			c.Users = slices.Delete(c.Users, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("no user named %s", name)
+}
+
+// Hash a token for storage and lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Resolve the models served to their providers. Without configured models,
+// every model of each provider with an API key is served, plus the current model
+func ResolveModels(cfg *config.Config, gatewayConfig *Config) (map[string]string, error) {
+	if len(gatewayConfig.Models) > 0 {
+		return gatewayConfig.Models, nil
+	}
+
+	models := make(map[string]string)
+	providers, err := config.ListProvidersWithKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		for _, model := range config.ProviderModels[provider] {
+			models[model] = provider
+		}
+	}
+	if cfg.CurrentProvider != "" && cfg.CurrentModel != "" {
+		models[cfg.CurrentModel] = cfg.CurrentProvider
+	}
+	return models, nil
+}
@@ -0,0 +1,390 @@
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+// Limit on request bodies
+const maxBodyBytes = 4 * 1024 * 1024
+
+// Create a provider answering with a model
+type ProviderFactory func(provider, model string) (ai.Provider, error)
+
+// OpenAI-compatible endpoint that forwards chat completions to the configured
+// providers, so users never see the vendor API keys
+type Gateway struct {
+	users     []User
+	models    map[string]string // Model to provider
+	factory   ProviderFactory
+	usage     *Usage
+	log       io.Writer // One line per request, nil to disable
+	mux       *http.ServeMux
+	mu        sync.Mutex
+	providers map[string]ai.Provider // Created on first use
+}
+
+// Body of a chat completion request. Other fields are ignored, since the
+// gateway config decides the generation parameters
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// A content part of a message. Only text parts are supported
+type contentPart struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type contextKey struct{}
+
+// Create a gateway serving the models to the users, logging usage
+func New(users []User, models map[string]string, factory ProviderFactory, usage *Usage, log io.Writer) *Gateway {
+	g := &Gateway{
+		users:     users,
+		models:    models,
+		factory:   factory,
+		usage:     usage,
+		log:       log,
+		mux:       http.NewServeMux(),
+		providers: make(map[string]ai.Provider),
+	}
+	g.mux.HandleFunc("GET /v1/models", g.handleModels)
+	g.mux.HandleFunc("POST /v1/chat/completions", g.handleChatCompletions)
+	return g
+}
+
+// Look up the user by bearer token, then route the request
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := g.authenticate(r)
+	if user == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "Incorrect API key provided")
+		return
+	}
+	g.mux.ServeHTTP(w, r.WithContext(withUser(r, user)))
+}
+
+// Find the user whose token hash matches the bearer token
+func (g *Gateway) authenticate(r *http.Request) *User {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	hash := []byte(HashToken(token))
+	for i := range g.users {
+		if subtle.ConstantTimeCompare(hash, []byte(g.users[i].TokenHash)) == 1 {
+			return &g.users[i]
+		}
+	}
+	return nil
+}
+
+// List the models the user may use
+func (g *Gateway) handleModels(w http.ResponseWriter, r *http.Request) {
+	user := userFrom(r)
+
+	models := []map[string]any{}
+	for _, model := range g.sortedModels() {
+		if !user.Allows(model) {
+			continue
+		}
+		models = append(models, map[string]any{
+			"id":       model,
+			"object":   "model",
+			"created":  0,
+			"owned_by": g.models[model],
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": models})
+}
+
+func (g *Gateway) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	user := userFrom(r)
+	started := time.Now()
+
+	var body chatRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err := decoder.Decode(&body); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", "", "request body too large")
+		} else {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", "", "invalid JSON body: "+err.Error())
+		}
+		return
+	}
+
+	providerName, served := g.models[body.Model]
+	if !served {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("The model %q does not exist", body.Model))
+		return
+	}
+	if !user.Allows(body.Model) {
+		writeError(w, http.StatusForbidden, "invalid_request_error", "model_not_allowed", fmt.Sprintf("You are not allowed to use the model %q", body.Model))
+		return
+	}
+
+	prompt, err := buildPrompt(body.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	reservedDay, err := g.usage.Reserve(user)
+	if err != nil {
+		code := "rate_limit_exceeded"
+		var quotaErr *QuotaError
+		if errors.As(err, &quotaErr) && quotaErr.Monthly {
+			code = "insufficient_quota"
+		}
+		rejected := UsageEntry{Time: started, User: user.Name, Model: body.Model, Provider: providerName, Status: http.StatusTooManyRequests, Error: err.Error()}
+		if logErr := g.usage.Record(rejected, ""); logErr != nil {
+			g.logf("failed to write usage log: %v", logErr)
+		}
+		g.logf("%s %s: %v", user.Name, body.Model, err)
+		writeError(w, http.StatusTooManyRequests, "rate_limit_error", code, err.Error())
+		return
+	}
+
+	entry := UsageEntry{Time: started, User: user.Name, Model: body.Model, Provider: providerName}
+	response, err := g.ask(r, providerName, body.Model, prompt)
+	entry.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		entry.Status = http.StatusBadGateway
+		if ai.IsRateLimited(err) {
+			entry.Status = http.StatusTooManyRequests
+		}
+		entry.Error = err.Error()
+	} else {
+		entry.Status = http.StatusOK
+		entry.InputTokens = response.Usage.InputTokens
+		entry.OutputTokens = response.Usage.OutputTokens
+	}
+	if logErr := g.usage.Record(entry, reservedDay); logErr != nil {
+		g.logf("failed to write usage log: %v", logErr)
+	}
+	g.logf("%s %s %d in=%d out=%d %dms", user.Name, body.Model, entry.Status, entry.InputTokens, entry.OutputTokens, entry.LatencyMs)
+
+	// Provider errors can include the gateway's configuration or API keys,
+	// so clients only get a generic message. The details are in the logs
+	if err != nil {
+		g.logf("%s %s: %v", user.Name, body.Model, err)
+		if entry.Status == http.StatusTooManyRequests {
+			writeError(w, entry.Status, "rate_limit_error", "", "The provider is rate limited, try again later")
+			return
+		}
+		writeError(w, entry.Status, "api_error", "", "The provider failed to answer, see the gateway logs for details")
+		return
+	}
+
+	id := fmt.Sprintf("chatcmpl-%d", started.UnixNano())
+	if body.Stream {
+		writeStream(w, id, started.Unix(), body.Model, response)
+		return
+	}
+
+	message := map[string]any{"role": "assistant", "content": response.RawResponse}
+	if response.Reasoning != "" {
+		message["reasoning_content"] = response.Reasoning
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": started.Unix(),
+		"model":   body.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": "stop",
+		}},
+		"usage": usageJSON(response.Usage),
+	})
+}
+
+// Ask the model's provider with the client's prompts
+func (g *Gateway) ask(r *http.Request, providerName, model string, prompt *ai.Prompt) (*ai.Response, error) {
+	provider, err := g.provider(providerName, model)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Ask(r.Context(), &ai.Request{Prompt: prompt})
+}
+
+// Get the provider for a model, creating it on first use
+func (g *Gateway) provider(providerName, model string) (ai.Provider, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if provider, ok := g.providers[model]; ok {
+		return provider, nil
+	}
+	provider, err := g.factory(providerName, model)
+	if err != nil {
+		return nil, err
+	}
+	g.providers[model] = provider
+	return provider, nil
+}
+
+func (g *Gateway) sortedModels() []string {
+	models := make([]string, 0, len(g.models))
+	for model := range g.models {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+	return models
+}
+
+func (g *Gateway) logf(format string, args ...any) {
+	if g.log != nil {
+		fmt.Fprintf(g.log, "%s "+format+"\n", append([]any{time.Now().Format(time.DateTime)}, args...)...)
+	}
+}
+
+// Join the messages into a system and user prompt. System and developer
+// messages form the system prompt; the rest are labelled by role when a
+// conversation has more than one
+func buildPrompt(messages []chatMessage) (*ai.Prompt, error) {
+	var system []string
+	type turn struct{ role, text string }
+	var turns []turn
+
+	for i, message := range messages {
+		text, err := messageText(message.Content)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		switch message.Role {
+		case "system", "developer":
+			system = append(system, text)
+		case "user", "assistant":
+			turns = append(turns, turn{message.Role, text})
+		default:
+			return nil, fmt.Errorf("messages[%d]: unsupported role %q", i, message.Role)
+		}
+	}
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("messages must include a user message")
+	}
+
+	prompt := &ai.Prompt{System: strings.Join(system, "\n\n")}
+	if len(turns) == 1 {
+		prompt.User = turns[0].text
+		return prompt, nil
+	}
+	parts := make([]string, len(turns))
+	for i, turn := range turns {
+		parts[i] = turn.role + ": " + turn.text
+	}
+	prompt.User = strings.Join(parts, "\n\n")
+	return prompt, nil
+}
+
+// Read message content, either a string or a list of text parts
+func messageText(content json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", fmt.Errorf("content must be a string or a list of parts")
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("unsupported content type %q", part.Type)
+		}
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// Write a finished answer as a chat completion stream: the content, the
+// finish reason with usage, then the end marker
+func writeStream(w http.ResponseWriter, id string, created int64, model string, response *ai.Response) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	chunk := func(delta map[string]any, finishReason any, usage any) {
+		data, _ := json.Marshal(map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"delta":         delta,
+				"finish_reason": finishReason,
+			}},
+			"usage": usage,
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+
+	delta := map[string]any{"role": "assistant", "content": response.RawResponse}
+	if response.Reasoning != "" {
+		delta["reasoning_content"] = response.Reasoning
+	}
+	chunk(delta, nil, nil)
+	chunk(map[string]any{}, "stop", usageJSON(response.Usage))
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func usageJSON(usage ai.Usage) map[string]int64 {
+	return map[string]int64{
+		"prompt_tokens":     usage.InputTokens,
+		"completion_tokens": usage.OutputTokens,
+		"total_tokens":      usage.InputTokens + usage.OutputTokens,
+	}
+}
+
+func withUser(r *http.Request, user *User) context.Context {
+	return context.WithValue(r.Context(), contextKey{}, user)
+}
+
+func userFrom(r *http.Request) *User {
+	return r.Context().Value(contextKey{}).(*User)
+}
+
+// Write a value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+// Write an error in the shape OpenAI clients expect
+func writeError(w http.ResponseWriter, status int, errType, code, message string) {
+	body := map[string]any{"message": message, "type": errType, "param": nil, "code": nil}
+	if code != "" {
+		body["code"] = code
+	}
+	writeJSON(w, status, map[string]any{"error": body})
+}
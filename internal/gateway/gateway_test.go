@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+func TestAuthenticate(t *testing.T) {
+	config := &Config{}
+	aliceToken, err := config.AddUser("alice", nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobToken, err := config.AddUser("bob", nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(config.Users, nil, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string // User name, empty for none
+	}{
+		{"first user", "Bearer " + aliceToken, "alice"},
+		{"second user", "Bearer " + bobToken, "bob"},
+		{"missing header", "", ""},
+		{"empty token", "Bearer ", ""},
+		{"wrong token", "Bearer how_0123", ""},
+		{"token hash", "Bearer " + HashToken(aliceToken), ""},
+		{"other scheme", "Basic " + aliceToken, ""},
+		{"lowercase scheme", "bearer " + aliceToken, ""},
+		{"token with suffix", "Bearer " + aliceToken + "x", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+			if test.authorization != "" {
+				r.Header.Set("Authorization", test.authorization)
+			}
+			user := g.authenticate(r)
+			got := ""
+			if user != nil {
+				got = user.Name
+			}
+			if got != test.want {
+				t.Errorf("authenticate() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Provider that fails with an error containing a secret
+type failingProvider struct{}
+
+func (failingProvider) Ask(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	return nil, errors.New("client config APIKey:\"secret-key\"")
+}
+
+func (failingProvider) GetName() string { return "failing" }
+
+func TestProviderErrorsAreNotSent(t *testing.T) {
+	config := &Config{}
+	token, err := config.AddUser("alice", nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage, err := OpenUsage(filepath.Join(t.TempDir(), "usage.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		factory ProviderFactory
+	}{
+		{"provider error", func(provider, model string) (ai.Provider, error) { return failingProvider{}, nil }},
+		{"factory error", func(provider, model string) (ai.Provider, error) {
+			return nil, errors.New("google client error: APIKey:\"secret-key\"")
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var log strings.Builder
+			g := New(config.Users, map[string]string{"m": "Google"}, test.factory, usage, &log)
+
+			body := `{"model": "m", "messages": [{"role": "user", "content": "hi"}]}`
+			r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, r)
+
+			response, _ := io.ReadAll(w.Result().Body)
+			if w.Code != http.StatusBadGateway {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadGateway)
+			}
+			if strings.Contains(string(response), "secret-key") {
+				t.Errorf("response contains the provider error: %s", response)
+			}
+			if !strings.Contains(log.String(), "secret-key") {
+				t.Errorf("log does not contain the provider error: %s", log.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// A single request in the usage log
+type UsageEntry struct {
+	Time         time.Time `json:"time"`
+	User         string    `json:"user"`
+	Model        string    `json:"model"`
+	Provider     string    `json:"provider"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	LatencyMs    int64     `json:"latency_ms"`
+	Status       int       `json:"status"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Why a quota check failed
+type QuotaError struct {
+	Monthly bool // Monthly token quota rather than daily requests
+	Limit   int64
+}
+
+func (e *QuotaError) Error() string {
+	if e.Monthly {
+		return fmt.Sprintf("monthly quota of %d tokens exceeded", e.Limit)
+	}
+	return fmt.Sprintf("daily quota of %d requests exceeded", e.Limit)
+}
+
+// Usage of a user in the current day and month
+type userUsage struct {
+	day      string
+	requests int
+	month    string
+	tokens   int64
+}
+
+// Appends usage to a JSONL log and tracks it against quotas
+type Usage struct {
+	mu    sync.Mutex
+	path  string
+	users map[string]*userUsage
+	now   func() time.Time
+}
+
+// Open the usage log, counting existing entries toward the current quotas
+func OpenUsage(path string) (*Usage, error) {
+	return openUsage(path, time.Now)
+}
+
+func openUsage(path string, now func() time.Time) (*Usage, error) {
+	usage := &Usage{path: path, users: make(map[string]*userUsage), now: now}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry UsageEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip lines cut off by a crash
+			continue
+		}
+		usage.count(entry.User, entry.Time, entry.Status < 400, entry.InputTokens+entry.OutputTokens)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage log: %w", err)
+	}
+	return usage, nil
+}
+
+// Get the usage of a user, resetting it when the day or month changes
+func (u *Usage) current(name string, at time.Time) *userUsage {
+	at = at.UTC()
+	day, month := at.Format("2006-01-02"), at.Format("2006-01")
+
+	usage, ok := u.users[name]
+	if !ok {
+		usage = &userUsage{day: day, month: month}
+		u.users[name] = usage
+	}
+	if usage.day != day {
+		usage.day, usage.requests = day, 0
+	}
+	if usage.month != month {
+		usage.month, usage.tokens = month, 0
+	}
+	return usage
+}
+
+func (u *Usage) count(name string, at time.Time, request bool, tokens int64) {
+	// Entries from past days or months don't count
+	now := u.now().UTC()
+	at = at.UTC()
+	if at.Format("2006-01") != now.Format("2006-01") {
+		return
+	}
+	usage := u.current(name, now)
+	if request && at.Format("2006-01-02") == usage.day {
+		usage.requests++
+	}
+	usage.tokens += tokens
+}
+
+// Reserve a request for a user, failing with a QuotaError when over quota.
+// Returns the day the request counts toward, for Record to release it if
+// the request fails
+func (u *Usage) Reserve(user *User) (string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := u.current(user.Name, u.now())
+	if user.MonthlyTokens > 0 && usage.tokens >= user.MonthlyTokens {
+		return "", &QuotaError{Monthly: true, Limit: user.MonthlyTokens}
+	}
+	if user.DailyRequests > 0 && usage.requests >= user.DailyRequests {
+		return "", &QuotaError{Limit: int64(user.DailyRequests)}
+	}
+	usage.requests++
+	return usage.day, nil
+}
+
+// Record a finished request and append it to the log. A failed request
+// releases its reservation, unless the day it was made has already ended.
+// reservedDay is empty for requests that were never reserved
+func (u *Usage) Record(entry UsageEntry, reservedDay string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := u.current(entry.User, u.now())
+	if reservedDay == usage.day && entry.Status >= 400 && usage.requests > 0 {
+		usage.requests--
+	}
+	usage.tokens += entry.InputTokens + entry.OutputTokens
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(u.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Get a user's requests today and tokens this month
+func (u *Usage) Totals(name string) (int, int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := u.current(name, u.now())
+	return usage.requests, usage.tokens
+}
@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReserveAndRecord(t *testing.T) {
+	day := time.Date(2026, 3, 14, 23, 59, 0, 0, time.UTC)
+	nextDay := day.Add(2 * time.Minute)
+	nextMonth := time.Date(2026, 4, 1, 0, 1, 0, 0, time.UTC)
+
+	type step struct {
+		at        time.Time
+		status    int   // Status recorded after a successful reservation, 0 to leave it pending
+		tokens    int64 // Tokens recorded with the status
+		wantQuota bool  // Reservation fails with a QuotaError
+		monthly   bool  // The QuotaError is for the monthly quota
+	}
+	tests := []struct {
+		name         string
+		user         User
+		steps        []step
+		wantRequests int
+		wantTokens   int64
+	}{
+		{
+			name: "daily quota",
+			user: User{Name: "alice", DailyRequests: 2},
+			steps: []step{
+				{at: day, status: 200},
+				{at: day, status: 200},
+				{at: day, wantQuota: true},
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "failed requests are released",
+			user: User{Name: "alice", DailyRequests: 1},
+			steps: []step{
+				{at: day, status: 502},
+				{at: day, status: 200},
+				{at: day, wantQuota: true},
+			},
+			wantRequests: 1,
+		},
+		{
+			name: "daily quota resets the next day",
+			user: User{Name: "alice", DailyRequests: 1},
+			steps: []step{
+				{at: day, status: 200},
+				{at: nextDay, status: 200},
+			},
+			wantRequests: 1,
+		},
+		{
+			name: "failure after midnight does not release the new day",
+			user: User{Name: "alice", DailyRequests: 1},
+			steps: []step{
+				{at: day},
+				{at: nextDay, status: 502},
+				{at: nextDay, status: 200},
+				{at: nextDay, wantQuota: true},
+			},
+			wantRequests: 1,
+		},
+		{
+			name: "monthly token quota",
+			user: User{Name: "alice", MonthlyTokens: 100},
+			steps: []step{
+				{at: day, status: 200, tokens: 60},
+				{at: day, status: 200, tokens: 60},
+				{at: day, wantQuota: true, monthly: true},
+				{at: nextMonth, status: 200, tokens: 10},
+			},
+			wantRequests: 1,
+			wantTokens:   10,
+		},
+		{
+			name: "no limits",
+			user: User{Name: "alice"},
+			steps: []step{
+				{at: day, status: 200, tokens: 1000},
+				{at: day, status: 200, tokens: 1000},
+			},
+			wantRequests: 2,
+			wantTokens:   2000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usage, err := OpenUsage(filepath.Join(t.TempDir(), "usage.jsonl"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var pending string
+			for i, step := range test.steps {
+				usage.now = func() time.Time { return step.at }
+				if pending != "" {
+					// Finish the request left pending by the previous step
+					if err := usage.Record(UsageEntry{Time: step.at, User: test.user.Name, Status: step.status, OutputTokens: step.tokens}, pending); err != nil {
+						t.Fatal(err)
+					}
+					pending = ""
+					continue
+				}
+
+				reservedDay, err := usage.Reserve(&test.user)
+				var quotaErr *QuotaError
+				if errors.As(err, &quotaErr) != step.wantQuota {
+					t.Fatalf("step %d: Reserve() error = %v, want quota error %v", i, err, step.wantQuota)
+				}
+				if step.wantQuota {
+					if quotaErr.Monthly != step.monthly {
+						t.Fatalf("step %d: monthly = %v, want %v", i, quotaErr.Monthly, step.monthly)
+					}
+					continue
+				}
+				if step.status == 0 {
+					pending = reservedDay
+					continue
+				}
+				if err := usage.Record(UsageEntry{Time: step.at, User: test.user.Name, Status: step.status, OutputTokens: step.tokens}, reservedDay); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			requests, tokens := usage.Totals(test.user.Name)
+			if requests != test.wantRequests || tokens != test.wantTokens {
+				t.Errorf("Totals() = %d, %d, want %d, %d", requests, tokens, test.wantRequests, test.wantTokens)
+			}
+		})
+	}
+}
+
+func TestOpenUsage(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	lastMonth := now.AddDate(0, -1, 0)
+
+	tests := []struct {
+		name         string
+		entries      []UsageEntry
+		extra        string // Appended after the entries
+		wantRequests int
+		wantTokens   int64
+	}{
+		{
+			name: "counts requests today and tokens this month",
+			entries: []UsageEntry{
+				{Time: now, User: "alice", Status: 200, InputTokens: 5, OutputTokens: 5},
+				{Time: now, User: "alice", Status: 200, InputTokens: 1},
+			},
+			wantRequests: 2,
+			wantTokens:   11,
+		},
+		{
+			name: "yesterday counts toward tokens only",
+			entries: []UsageEntry{
+				{Time: yesterday, User: "alice", Status: 200, InputTokens: 4},
+			},
+			wantTokens: 4,
+		},
+		{
+			name: "skips failed requests and other users",
+			entries: []UsageEntry{
+				{Time: now, User: "alice", Status: 429},
+				{Time: now, User: "bob", Status: 200, InputTokens: 7},
+			},
+		},
+		{
+			name: "skips past months",
+			entries: []UsageEntry{
+				{Time: lastMonth, User: "alice", Status: 200, InputTokens: 50},
+			},
+		},
+		{
+			name: "skips lines cut off by a crash",
+			entries: []UsageEntry{
+				{Time: now, User: "alice", Status: 200, InputTokens: 3},
+			},
+			extra:        `{"time":`,
+			wantRequests: 1,
+			wantTokens:   3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "usage.jsonl")
+			var data []byte
+			for _, entry := range test.entries {
+				line, err := json.Marshal(entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = append(append(data, line...), '\n')
+			}
+			data = append(data, test.extra...)
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			usage, err := openUsage(path, func() time.Time { return now })
+			if err != nil {
+				t.Fatal(err)
+			}
+			requests, tokens := usage.Totals("alice")
+			if requests != test.wantRequests || tokens != test.wantTokens {
+				t.Errorf("Totals() = %d, %d, want %d, %d", requests, tokens, test.wantRequests, test.wantTokens)
+			}
+		})
+	}
+}
+
+func TestOpenUsageMissing(t *testing.T) {
+	usage, err := OpenUsage(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests, tokens := usage.Totals("alice"); requests != 0 || tokens != 0 {
+		t.Errorf("Totals() = %d, %d, want 0, 0", requests, tokens)
+	}
+}
@@ -0,0 +1,157 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/config"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Number of entries kept, oldest are dropped first
+const maxEntries = 1000
+
+// How long to wait for another process to finish writing the history, and
+// the age after which a lock left by a crashed process is removed
+const (
+	lockTimeout = 5 * time.Second
+	staleLock   = 30 * time.Second
+)
+
+// A question and the answer it received
+type Entry struct {
+	Question  string             `json:"question"`
+	SysInfo   *system.SystemInfo `json:"system"`
+	Provider  string             `json:"provider"`
+	Model     string             `json:"model"`
+	Response  *ai.Response       `json:"response"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// Get the path to the history file
+func GetHistoryPath() (string, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "history.jsonl"), nil
+}
+
+// Load history from disk, oldest first.
+// Lines that can't be parsed are skipped
+func Load() ([]Entry, error) {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Response == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// Append an entry to the history, dropping the oldest entries past the limit
+func Append(entry Entry) error {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Other processes, such as how serve, may be appending at the same time
+	unlock, err := lock(historyPath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	entries, err := Load()
+	if err != nil || len(entries) <= maxEntries {
+		return err
+	}
+	return save(historyPath, entries[len(entries)-maxEntries:])
+}
+
+// Rewrite the history file with entries
+func save(historyPath string, entries []Entry) error {
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	// Write to a temp file first so a failed write doesn't lose history
+	tmpPath := historyPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return os.Rename(tmpPath, historyPath)
+}
+
+// Take a lock shared between processes by creating the lock file exclusively.
+// Returns a function that releases it
+func lock(lockPath string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock history: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for history lock %s", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+func newEntry(question string) Entry {
+	return Entry{
+		Question:  question,
+		Response:  &ai.Response{Commands: []string{"echo " + question}},
+		Timestamp: time.Now(),
+	}
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if entries, err := Load(); err != nil || entries != nil {
+		t.Fatalf("Load() of missing history = %v, %v", entries, err)
+	}
+	for _, question := range []string{"one", "two"} {
+		if err := Append(newEntry(question)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Lines that can't be parsed or have no response are skipped
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(file, `not json`)
+	fmt.Fprintln(file, `{"question":"no response"}`)
+	file.Close()
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Question != "one" || entries[1].Question != "two" {
+		t.Errorf("Load() = %+v", entries)
+	}
+}
+
+func TestAppendTrims(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []Entry
+	for i := range maxEntries {
+		entries = append(entries, newEntry(fmt.Sprint(i)))
+	}
+	if err := save(historyPath, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(newEntry("newest")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxEntries {
+		t.Fatalf("len = %d, want %d", len(entries), maxEntries)
+	}
+	if entries[0].Question != "1" || entries[len(entries)-1].Question != "newest" {
+		t.Errorf("kept %q to %q, want the oldest dropped", entries[0].Question, entries[len(entries)-1].Question)
+	}
+	if _, err := os.Stat(historyPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temp file left behind")
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Append(newEntry(fmt.Sprint(i)))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != writers {
+		t.Errorf("len = %d, want %d", len(entries), writers)
+	}
+}
+
+func TestLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "history.jsonl.lock")
+
+	unlock, err := lock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("lock file not created: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatal("lock file not removed")
+	}
+
+	// A lock left by a crashed process is taken over once stale
+	if err := os.WriteFile(lockPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	unlock, err = lock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if time.Since(start) > time.Second {
+		t.Error("waited for a stale lock")
+	}
+}
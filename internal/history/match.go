@@ -0,0 +1,62 @@
+package history
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Words dropped from the start of questions when comparing them, so
+// "how do I undo the last commit" matches "undo the last commit". Words in
+// the middle are kept, since "convert a to b" differs from "convert b to a"
+var fillerWords = []string{"how", "do", "does", "i", "can", "to", "the", "a", "an", "you", "me", "please", "my"}
+
+// Words dropped from the end of questions
+var trailingFillerWords = []string{"please"}
+
+// Normalize a question for comparison: lowercase, without punctuation or
+// leading and trailing filler words
+func NormalizeQuestion(question string) string {
+	fields := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '-' && r != '.' && r != '/'
+	})
+
+	var words []string
+	for _, field := range fields {
+		// Keep leading dots, as in ./src or .bashrc
+		if field = strings.TrimRight(field, "."); field != "" {
+			words = append(words, field)
+		}
+	}
+	for len(words) > 0 && slices.Contains(fillerWords, words[0]) {
+		words = words[1:]
+	}
+	for len(words) > 0 && slices.Contains(trailingFillerWords, words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// Find the newest entry for the same question asked on the same OS and shell,
+// answered by the same provider and model
+func Find(entries []Entry, question string, sysInfo *system.SystemInfo, provider, model string) *Entry {
+	normalized := NormalizeQuestion(question)
+	if normalized == "" {
+		return nil
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Provider != provider || entry.Model != model {
+			continue
+		}
+		if entry.SysInfo == nil || entry.SysInfo.OS != sysInfo.OS || entry.SysInfo.OSName != sysInfo.OSName || entry.SysInfo.Shell != sysInfo.Shell {
+			continue
+		}
+		if NormalizeQuestion(entry.Question) == normalized {
+			return &entries[i]
+		}
+	}
+	return nil
+}
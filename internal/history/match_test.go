@@ -0,0 +1,86 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+func TestNormalizeQuestion(t *testing.T) {
+	tests := []struct {
+		question string
+		want     string
+	}{
+		{"How do I undo the last commit?", "undo the last commit"},
+		{"undo the last commit, please", "undo the last commit"},
+		{"Can you list files in ./src", "list files in ./src"},
+		{"convert a to b", "convert a to b"},
+		{"convert b to a", "convert b to a"},
+		{"kill port 8080!", "kill port 8080"},
+		{"resize image-1.png", "resize image-1.png"},
+		{"edit .bashrc.", "edit .bashrc"},
+		{"how do i", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := NormalizeQuestion(test.question); got != test.want {
+			t.Errorf("NormalizeQuestion(%q) = %q, want %q", test.question, got, test.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	linux := &system.SystemInfo{OS: "linux", OSName: "Ubuntu", Shell: "bash"}
+	mac := &system.SystemInfo{OS: "darwin", OSName: "macOS", Shell: "zsh"}
+	entry := func(question string, sysInfo *system.SystemInfo, model, command string) Entry {
+		return Entry{
+			Question: question,
+			SysInfo:  sysInfo,
+			Provider: "openai",
+			Model:    model,
+			Response: &ai.Response{Commands: []string{command}},
+		}
+	}
+	entries := []Entry{
+		entry("how do I undo the last commit", linux, "gpt-5", "old"),
+		entry("undo the last commit", linux, "gpt-5", "new"),
+		entry("undo the last commit", mac, "gpt-5", "mac"),
+		entry("undo the last commit", linux, "gpt-5-mini", "mini"),
+		entry("convert a to b", linux, "gpt-5", "a-to-b"),
+		{Question: "undo the last commit", Provider: "openai", Model: "gpt-5", Response: &ai.Response{}},
+	}
+
+	tests := []struct {
+		name     string
+		question string
+		sysInfo  *system.SystemInfo
+		model    string
+		want     string
+	}{
+		{"newest match", "How do I undo the last commit?", linux, "gpt-5", "new"},
+		{"other system", "undo the last commit", mac, "gpt-5", "mac"},
+		{"other model", "undo the last commit", linux, "gpt-5-mini", "mini"},
+		{"unknown model", "undo the last commit", linux, "o3", ""},
+		{"word order matters", "convert b to a", linux, "gpt-5", ""},
+		{"only filler", "how do I", linux, "gpt-5", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found := Find(entries, test.question, test.sysInfo, "openai", test.model)
+			var got string
+			if found != nil {
+				got = found.Response.Commands[0]
+			}
+			if got != test.want {
+				t.Errorf("Find(%q) = %q, want %q", test.question, got, test.want)
+			}
+		})
+	}
+
+	if Find(entries, "undo the last commit", linux, "anthropic", "gpt-5") != nil {
+		t.Error("Find() matched another provider")
+	}
+}
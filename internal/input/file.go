@@ -0,0 +1,133 @@
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+// Matches a trailing line range such as "10", "10-20" or "10-"
+var lineRangePattern = regexp.MustCompile(`^(\d+)(?:-(\d*))?$`)
+
+// Number of leading bytes checked when detecting binary files
+const binarySniffSize = 8000
+
+// Read a file as an attachment, truncated to budget bytes.
+// spec is a path with an optional line range, e.g. "Makefile:10-20"
+func ReadFile(spec string, budget int) (*ai.Attachment, error) {
+	path, start, end, err := parseFileSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	// Read one byte past the limit to tell if the file was cut off
+	data, err := io.ReadAll(io.LimitReader(file, maxRead+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	cutOff := len(data) > maxRead
+	if cutOff {
+		data = data[:maxRead]
+	}
+	if isBinary(data) {
+		return nil, fmt.Errorf("%s appears to be a binary file", path)
+	}
+
+	content := string(data)
+	name := filepath.Clean(path)
+	if start > 0 {
+		content, err = selectLines(content, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch end {
+		case start:
+			name = fmt.Sprintf("%s:%d", name, start)
+		case 0:
+			name = fmt.Sprintf("%s:%d-", name, start)
+		default:
+			name = fmt.Sprintf("%s:%d-%d", name, start, end)
+		}
+	}
+
+	// Keep most of the start, where headers and declarations usually are
+	content, truncated := truncate(content, budget, budget*3/4)
+	return &ai.Attachment{
+		Name:      name,
+		Content:   content,
+		Truncated: truncated || cutOff,
+	}, nil
+}
+
+// Split a file spec into its path and 1-based line range.
+// start is 0 when no range is given, end is 0 when the range is open
+func parseFileSpec(spec string) (string, int, int, error) {
+	// A file whose name ends in ":N" takes precedence over a range
+	i := strings.LastIndex(spec, ":")
+	if i <= 0 {
+		return spec, 0, 0, nil
+	}
+	if _, err := os.Stat(spec); err == nil {
+		return spec, 0, 0, nil
+	}
+
+	// Only treat the suffix as a range if it looks like one, so paths such
+	// as "C:\file" are left alone
+	match := lineRangePattern.FindStringSubmatch(spec[i+1:])
+	if match == nil {
+		return spec, 0, 0, nil
+	}
+
+	start, _ := strconv.Atoi(match[1])
+	end := start
+	if strings.Contains(spec[i+1:], "-") {
+		end = 0
+		if match[2] != "" {
+			end, _ = strconv.Atoi(match[2])
+		}
+	}
+
+	if start < 1 || (end != 0 && end < start) {
+		return "", 0, 0, fmt.Errorf("invalid line range in %s", spec)
+	}
+	return spec[:i], start, end, nil
+}
+
+// Select a 1-based inclusive range of lines. end of 0 selects to the end of the file
+func selectLines(content string, start, end int) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if start > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of the file", start)
+	}
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start-1:end], ""), nil
+}
+
+// Check for NUL bytes or invalid UTF-8 near the start of the data
+func isBinary(data []byte) bool {
+	sniff := data
+	if len(sniff) > binarySniffSize {
+		sniff = sniff[:binarySniffSize]
+		// Don't count a multi-byte character cut off by the sniff limit
+		for i := 0; i < utf8.UTFMax && len(sniff) > 0 && !utf8.Valid(sniff); i++ {
+			sniff = sniff[:len(sniff)-1]
+		}
+	}
+	return bytes.IndexByte(sniff, 0) >= 0 || !utf8.Valid(sniff)
+}
@@ -0,0 +1,130 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFileSpec(t *testing.T) {
+	dir := t.TempDir()
+	colonFile := filepath.Join(dir, "notes:10")
+	if err := os.WriteFile(colonFile, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		spec      string
+		wantPath  string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{"plain path", "Makefile", "Makefile", 0, 0, false},
+		{"single line", "Makefile:10", "Makefile", 10, 10, false},
+		{"range", "Makefile:10-20", "Makefile", 10, 20, false},
+		{"open range", "Makefile:10-", "Makefile", 10, 0, false},
+		{"windows drive", `C:\file`, `C:\file`, 0, 0, false},
+		{"not a range", "host:port", "host:port", 0, 0, false},
+		{"existing file with colon", colonFile, colonFile, 0, 0, false},
+		{"zero start", "Makefile:0", "", 0, 0, true},
+		{"reversed range", "Makefile:20-10", "", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, start, end, err := parseFileSpec(test.spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseFileSpec(%q) error = %v, want error %v", test.spec, err, test.wantErr)
+			}
+			if path != test.wantPath || start != test.wantStart || end != test.wantEnd {
+				t.Errorf("parseFileSpec(%q) = %q, %d, %d, want %q, %d, %d",
+					test.spec, path, start, end, test.wantPath, test.wantStart, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+
+	tests := []struct {
+		name    string
+		start   int
+		end     int
+		want    string
+		wantErr bool
+	}{
+		{"single line", 2, 2, "two\n", false},
+		{"range", 2, 3, "two\nthree\n", false},
+		{"open range", 3, 0, "three\nfour\n", false},
+		{"end past file", 4, 10, "four\n", false},
+		{"start past file", 10, 0, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := selectLines(content, test.start, test.end)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("selectLines() error = %v, want error %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("selectLines() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	// A multi-byte character straddling the sniff limit
+	straddle := []byte(strings.Repeat("a", binarySniffSize-1) + "é")
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"text", []byte("hello\n"), false},
+		{"utf-8", []byte("héllo wörld"), false},
+		{"nul byte", []byte("hel\x00lo"), true},
+		{"invalid utf-8", []byte{0xff, 0xfe, 'a'}, true},
+		{"character at sniff limit", straddle, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isBinary(test.data); got != test.want {
+				t.Errorf("isBinary() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Makefile")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "binary")
+	if err := os.WriteFile(binary, []byte{0, 1, 2}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	attachment, err := ReadFile(path+":2-3", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.Name != path+":2-3" || attachment.Content != "two\nthree\n" || attachment.Truncated {
+		t.Errorf("ReadFile() = %+v", attachment)
+	}
+
+	if _, err := ReadFile(binary, 1000); err == nil {
+		t.Error("ReadFile() of a binary file returned no error")
+	}
+	if _, err := ReadFile(filepath.Join(dir, "missing"), 1000); err == nil {
+		t.Error("ReadFile() of a missing file returned no error")
+	}
+}
@@ -0,0 +1,87 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+// Upper bound on piped input or a file read into memory before truncation
+const maxRead = 64 * 1024 * 1024
+
+// Check if stdin is piped or redirected rather than a terminal
+func StdinIsPiped() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice == 0
+}
+
+// Check if stdout is a terminal rather than a pipe or file
+func StdoutIsTerminal() bool {
+	stat, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Read piped stdin as an attachment, truncated to budget bytes.
+// Returns nil if stdin is empty
+func ReadStdin(budget int) (*ai.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxRead+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading stdin: %w", err)
+	}
+	cutOff := len(data) > maxRead
+	if cutOff {
+		data = data[:maxRead]
+	}
+
+	content := string(data)
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+
+	// Keep most of the end, since errors usually appear last in logs
+	content, truncated := truncate(content, budget, budget/4)
+	return &ai.Attachment{
+		Name:      "stdin",
+		Content:   content,
+		Truncated: truncated || cutOff,
+	}, nil
+}
+
+// Truncate content to roughly budget bytes, keeping headSize bytes from
+// the start and the rest of the budget from the end
+func truncate(content string, budget, headSize int) (string, bool) {
+	if budget <= 0 || len(content) <= budget {
+		return content, false
+	}
+
+	tailSize := budget - headSize
+
+	// Cut on line boundaries where possible
+	head := content[:headSize]
+	if i := strings.LastIndex(head, "\n"); i > 0 {
+		head = head[:i+1]
+	}
+	tail := content[len(content)-tailSize:]
+	if i := strings.Index(tail, "\n"); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+
+	// Don't split multi-byte characters
+	head = strings.ToValidUTF8(head, "")
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+
+	omitted := len(content) - len(head) - len(tail)
+	return fmt.Sprintf("%s\n... [%d bytes omitted] ...\n%s", strings.TrimRight(head, "\n"), omitted, tail), true
+}
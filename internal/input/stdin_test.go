@@ -0,0 +1,62 @@
+package input
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	lines := strings.Repeat("line of log output\n", 100)
+
+	tests := []struct {
+		name          string
+		content       string
+		budget        int
+		headSize      int
+		wantTruncated bool
+	}{
+		{"under budget", "short", 100, 25, false},
+		{"exact budget", "12345", 5, 1, false},
+		{"no budget", lines, 0, 0, false},
+		{"over budget", lines, 200, 50, true},
+		{"multi-byte", strings.Repeat("héllo wörld ", 100), 101, 33, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, truncated := truncate(test.content, test.budget, test.headSize)
+			if truncated != test.wantTruncated {
+				t.Fatalf("truncated = %v, want %v", truncated, test.wantTruncated)
+			}
+			if !truncated {
+				if got != test.content {
+					t.Errorf("content changed without truncation")
+				}
+				return
+			}
+			if !strings.Contains(got, "bytes omitted") {
+				t.Errorf("missing omission marker in %q", got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncated content is not valid UTF-8")
+			}
+			// Allow for the marker line
+			if len(got) > test.budget+40 {
+				t.Errorf("len = %d, want about %d", len(got), test.budget)
+			}
+		})
+	}
+}
+
+func TestTruncateKeepsEnds(t *testing.T) {
+	content := "first line\n" + strings.Repeat("middle\n", 100) + "last line\n"
+
+	got, _ := truncate(content, 100, 25)
+	if !strings.HasPrefix(got, "first line\n") {
+		t.Errorf("head lost: %q", got)
+	}
+	if !strings.HasSuffix(got, "last line\n") {
+		t.Errorf("tail lost: %q", got)
+	}
+}
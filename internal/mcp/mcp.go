@@ -0,0 +1,350 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Latest protocol version supported, used when the client asks for an unknown one
+const protocolVersion = "2025-06-18"
+
+var supportedVersions = []string{"2024-11-05", "2025-03-26", protocolVersion}
+
+// Limit on a single message read from stdin
+const maxMessageBytes = 10 * 1024 * 1024
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Model Context Protocol server answering tool calls with a provider
+type Server struct {
+	provider ai.Provider
+	sysInfo  *system.SystemInfo
+	model    string
+	version  string
+	log      io.Writer // Provider errors are written here, nil to disable
+
+	writeMu  sync.Mutex
+	out      *json.Encoder
+	writeErr error // First failed write, after which nothing more is written
+
+	cancelMu sync.Mutex
+	cancels  map[string]context.CancelFunc // In-flight tool calls by request ID
+}
+
+// JSON-RPC request, notification or response from the client
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// A tool offered to clients
+type tool struct {
+	Name        string         `json:"name"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	Annotations map[string]any `json:"annotations"`
+}
+
+// Arguments shared by the tools
+type toolArguments struct {
+	Question string `json:"question"`
+	Command  string `json:"command"`
+	OS       string `json:"os"`
+	Shell    string `json:"shell"`
+}
+
+// Tool call result, with the answer as text for older clients
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Create a server answering with the provider for the detected system
+func New(provider ai.Provider, sysInfo *system.SystemInfo, model, version string, log io.Writer) *Server {
+	return &Server{
+		provider: provider,
+		sysInfo:  sysInfo,
+		model:    model,
+		version:  version,
+		log:      log,
+		cancels:  make(map[string]context.CancelFunc),
+	}
+}
+
+// Read newline-delimited JSON-RPC messages from in and write replies to out
+// until in is closed or the context is cancelled. Tool calls run concurrently.
+// Stops with an error once a reply cannot be written
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = json.NewEncoder(out)
+	s.out.SetEscapeHTML(false)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	// Cancels in-flight tool calls when serving stops
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	for scanner.Scan() {
+		// Tool calls write from their own goroutines
+		if err := s.failedWrite(); err != nil {
+			return err
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			if err := s.writeError(json.RawMessage("null"), codeParseError, "parse error: "+err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case msg.Method == "":
+			// Responses to requests, which this server never sends
+		case msg.ID == nil:
+			s.handleNotification(msg)
+		case msg.Method == "tools/call":
+			callCtx, cancel := context.WithCancel(ctx)
+			s.cancelMu.Lock()
+			s.cancels[string(msg.ID)] = cancel
+			s.cancelMu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleToolCall(callCtx, msg)
+				s.cancelMu.Lock()
+				delete(s.cancels, string(msg.ID))
+				s.cancelMu.Unlock()
+				cancel()
+			}()
+		default:
+			if err := s.handleRequest(msg); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read message: %w", err)
+	}
+	wg.Wait()
+	return s.failedWrite()
+}
+
+// Handle requests other than tool calls, returning an error if the reply
+// cannot be written
+func (s *Server) handleRequest(msg message) error {
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		version := protocolVersion
+		if slices.Contains(supportedVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return s.writeResult(msg.ID, map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "how", "version": s.version},
+			"instructions":    "Suggests and explains terminal commands for the user's OS and shell. Commands are never run.",
+		})
+	case "ping":
+		return s.writeResult(msg.ID, map[string]any{})
+	case "tools/list":
+		return s.writeResult(msg.ID, map[string]any{"tools": tools()})
+	default:
+		return s.writeError(msg.ID, codeMethodNotFound, "method not found: "+msg.Method)
+	}
+}
+
+// Cancel a tool call when the client gives up on it, ignore other notifications
+func (s *Server) handleNotification(msg message) {
+	if msg.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	if cancel, ok := s.cancels[string(params.RequestID)]; ok {
+		cancel()
+	}
+}
+
+// Ask the provider for a tool call. Provider errors are returned as generic
+// tool errors, since they can include configuration or API keys
+func (s *Server) handleToolCall(ctx context.Context, msg message) {
+	var params struct {
+		Name      string        `json:"name"`
+		Arguments toolArguments `json:"arguments"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.writeError(msg.ID, codeInvalidParams, "invalid params: "+err.Error())
+		return
+	}
+
+	args := params.Arguments
+	request := &ai.Request{SysInfo: s.sysInfo.ForTarget(args.OS, args.Shell)}
+	switch params.Name {
+	case "suggest_command":
+		request.Question = strings.TrimSpace(args.Question)
+		if request.Question == "" {
+			s.writeError(msg.ID, codeInvalidParams, "missing question")
+			return
+		}
+	case "explain_command":
+		request.Question = strings.TrimSpace(args.Command)
+		request.Explain = true
+		if request.Question == "" {
+			s.writeError(msg.ID, codeInvalidParams, "missing command")
+			return
+		}
+	default:
+		s.writeError(msg.ID, codeInvalidParams, "unknown tool: "+params.Name)
+		return
+	}
+
+	response, err := s.provider.Ask(ctx, request)
+	if ctx.Err() != nil {
+		// Cancelled requests get no response
+		return
+	}
+	if err != nil {
+		if s.log != nil {
+			fmt.Fprintf(s.log, "%s %s: %v\n", time.Now().Format(time.DateTime), params.Name, err)
+		}
+		s.writeResult(msg.ID, toolResult{
+			Content: []textContent{{Type: "text", Text: "Error: provider request failed"}},
+			IsError: true,
+		})
+		return
+	}
+
+	answer := ai.NewAnswerJSON(request, response, s.provider.GetName(), s.model)
+	text, err := json.Marshal(answer)
+	if err != nil {
+		s.writeError(msg.ID, codeInternalError, err.Error())
+		return
+	}
+	s.writeResult(msg.ID, toolResult{
+		Content:           []textContent{{Type: "text", Text: string(text)}},
+		StructuredContent: answer,
+	})
+}
+
+// Tools offered to clients
+func tools() []tool {
+	targetProperties := map[string]any{
+		"os": map[string]any{
+			"type":        "string",
+			"description": "Target OS name, e.g. macOS, Ubuntu or Windows. Defaults to the user's OS",
+		},
+		"shell": map[string]any{
+			"type":        "string",
+			"description": "Target shell, e.g. bash, zsh, fish or powershell. Defaults to the user's shell",
+		},
+	}
+	schema := func(name, description string) map[string]any {
+		properties := map[string]any{name: map[string]any{"type": "string", "description": description}}
+		for key, value := range targetProperties {
+			properties[key] = value
+		}
+		return map[string]any{"type": "object", "properties": properties, "required": []string{name}}
+	}
+	annotations := map[string]any{"readOnlyHint": true, "openWorldHint": true}
+
+	return []tool{
+		{
+			Name:        "suggest_command",
+			Title:       "Suggest command",
+			Description: "Suggest a terminal command or script for a task on the user's OS and shell. Returns a title, description, commands, placeholders to fill in, a risk level and the system it is for. The command is not run.",
+			InputSchema: schema("question", "What to do, e.g. 'find files larger than 1GB'"),
+			Annotations: annotations,
+		},
+		{
+			Name:        "explain_command",
+			Title:       "Explain command",
+			Description: "Explain what a terminal command does and any side effects. Returns a one-line summary as the title, an explanation as the description, the command (corrected if invalid for the shell), a risk level and the system it is for.",
+			InputSchema: schema("command", "The command to explain, e.g. 'tar -xzvf archive.tgz'"),
+			Annotations: annotations,
+		},
+	}
+}
+
+func (s *Server) writeResult(id json.RawMessage, result any) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) writeError(id json.RawMessage, code int, message string) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}})
+}
+
+// Write a message, one per line. After a failed write every later one fails
+// with the same error
+func (s *Server) write(msg response) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	if err := s.out.Encode(msg); err != nil {
+		s.writeErr = fmt.Errorf("failed to write message: %w", err)
+	}
+	return s.writeErr
+}
+
+// The error from the first failed write, if any
+func (s *Server) failedWrite() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.writeErr
+}
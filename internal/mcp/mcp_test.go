@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Provider that answers with the question, fails with err, or blocks until
+// the request is cancelled when block is set
+type fakeProvider struct {
+	err     error
+	block   bool
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (p *fakeProvider) Ask(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	if p.block {
+		close(p.started)
+		<-ctx.Done()
+		close(p.stopped)
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ai.Response{Title: "answer", Commands: []string{"echo " + req.Question + " in " + req.SysInfo.Shell}}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "test"
+}
+
+var testSystem = &system.SystemInfo{OS: "linux", OSName: "Ubuntu", Shell: "bash"}
+
+// Serve the messages and return the replies by request ID
+func serve(t *testing.T, server *Server, messages ...string) map[string]reply {
+	t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+	return readReplies(t, &out)
+}
+
+// A response as read by the client
+type reply struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func readReplies(t *testing.T, out io.Reader) map[string]reply {
+	t.Helper()
+	replies := make(map[string]reply)
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var r reply
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		replies[string(r.ID)] = r
+	}
+	return replies
+}
+
+func TestInitializeAndList(t *testing.T) {
+	replies := serve(t, New(&fakeProvider{}, testSystem, "m", "1.0", nil),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+		`{not json`,
+	)
+
+	var initialized struct {
+		ProtocolVersion string            `json:"protocolVersion"`
+		ServerInfo      map[string]string `json:"serverInfo"`
+	}
+	json.Unmarshal(replies["1"].Result, &initialized)
+	if initialized.ProtocolVersion != "2024-11-05" || initialized.ServerInfo["version"] != "1.0" {
+		t.Errorf("initialize = %+v", initialized)
+	}
+	json.Unmarshal(replies["2"].Result, &initialized)
+	if initialized.ProtocolVersion != protocolVersion {
+		t.Errorf("unknown version answered with %q, want %q", initialized.ProtocolVersion, protocolVersion)
+	}
+
+	var listed struct {
+		Tools []tool `json:"tools"`
+	}
+	json.Unmarshal(replies["3"].Result, &listed)
+	if len(listed.Tools) != 2 || listed.Tools[0].Name != "suggest_command" || listed.Tools[1].Name != "explain_command" {
+		t.Errorf("tools/list = %+v", listed.Tools)
+	}
+
+	if reply := replies["4"]; reply.Error == nil || reply.Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method = %+v, want method not found", reply)
+	}
+	if reply := replies["null"]; reply.Error == nil || reply.Error.Code != codeParseError {
+		t.Errorf("invalid JSON = %+v, want a parse error", reply)
+	}
+	if len(replies) != 5 {
+		t.Errorf("got %d replies, want 5 with none for the notification", len(replies))
+	}
+}
+
+func TestToolCall(t *testing.T) {
+	replies := serve(t, New(&fakeProvider{}, testSystem, "m", "1.0", nil),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"suggest_command","arguments":{"question":"list files"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"explain_command","arguments":{"command":"ls","shell":"fish"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"suggest_command","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"run_command","arguments":{}}}`,
+	)
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"1", "echo list files in bash"},
+		{"2", "echo ls in fish"},
+	}
+	for _, test := range tests {
+		var result toolResult
+		json.Unmarshal(replies[test.id].Result, &result)
+		if result.IsError || len(result.Content) != 1 || !strings.Contains(result.Content[0].Text, test.want) {
+			t.Errorf("call %s = %+v, want %q", test.id, result, test.want)
+		}
+		if result.StructuredContent == nil {
+			t.Errorf("call %s has no structured content", test.id)
+		}
+	}
+
+	if reply := replies["3"]; reply.Error == nil || reply.Error.Message != "missing question" {
+		t.Errorf("call without question = %+v", reply)
+	}
+	if reply := replies["4"]; reply.Error == nil || reply.Error.Code != codeInvalidParams {
+		t.Errorf("unknown tool = %+v", reply)
+	}
+}
+
+func TestToolCallProviderError(t *testing.T) {
+	var log bytes.Buffer
+	replies := serve(t, New(&fakeProvider{err: errors.New("401 invalid key sk-secret")}, testSystem, "m", "1.0", &log),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"suggest_command","arguments":{"question":"q"}}}`,
+	)
+
+	var result toolResult
+	json.Unmarshal(replies["1"].Result, &result)
+	if !result.IsError || len(result.Content) != 1 || result.Content[0].Text != "Error: provider request failed" {
+		t.Errorf("result = %+v, want a generic tool error", result)
+	}
+	if !strings.Contains(log.String(), "sk-secret") {
+		t.Errorf("log = %q, want the provider error", log.String())
+	}
+}
+
+func TestCancel(t *testing.T) {
+	provider := &fakeProvider{block: true, started: make(chan struct{}), stopped: make(chan struct{})}
+	in, client := io.Pipe()
+	var out bytes.Buffer
+	errs := make(chan error, 1)
+	go func() {
+		errs <- New(provider, testSystem, "m", "1.0", nil).Serve(context.Background(), in, &out)
+	}()
+
+	io.WriteString(client, `{"jsonrpc":"2.0","id":"call","method":"tools/call","params":{"name":"suggest_command","arguments":{"question":"q"}}}`+"\n")
+	<-provider.started
+	io.WriteString(client, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call"}}`+"\n")
+	<-provider.stopped
+	client.Close()
+
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("cancelled call was answered: %s", out.String())
+	}
+}
+
+// Writer that always fails
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestServeStopsOnWriteError(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+	}{
+		{"request", []string{`{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"jsonrpc":"2.0","id":2,"method":"ping"}`}},
+		{"tool call", []string{`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"suggest_command","arguments":{"question":"q"}}}`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := New(&fakeProvider{}, testSystem, "m", "1.0", nil)
+			err := server.Serve(context.Background(), strings.NewReader(strings.Join(test.messages, "\n")), failingWriter{})
+			if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+				t.Errorf("Serve() = %v, want the write error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package saved
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/config"
+)
+
+// Letters, digits, dots, dashes and underscores, so it can be recalled as "how @name"
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// A saved answer that can be recalled by name
+type Snippet struct {
+	Name     string       `json:"name"`
+	Tags     []string     `json:"tags,omitempty"`
+	Question string       `json:"question"`
+	OS       string       `json:"os,omitempty"`    // OS name it was saved on, e.g. "macOS"
+	Shell    string       `json:"shell,omitempty"` // Shell it was saved for
+	Response *ai.Response `json:"response"`
+	Created  time.Time    `json:"created"`
+}
+
+// Check a snippet name can be used with "how @name"
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("name must start with a letter or digit and contain only letters, digits, '.', '-' and '_'")
+	}
+	return nil
+}
+
+// Split a comma or space separated list of tags
+func ParseTags(tags string) []string {
+	var parsed []string
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' }) {
+		parsed = append(parsed, strings.ToLower(tag))
+	}
+	return parsed
+}
+
+// Turn a title into a default snippet name, e.g. "Kill Port 8080" -> "kill-port-8080"
+func Slugify(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// Get the path to the saved snippets file
+func GetSavedPath() (string, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "saved.json"), nil
+}
+
+// Load saved snippets from disk. Snippets without a response, e.g. from a
+// hand-edited file, get an empty one so they can still be listed and deleted
+func Load() ([]Snippet, error) {
+	savedPath, err := GetSavedPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(savedPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snippets []Snippet
+	if err := json.Unmarshal(data, &snippets); err != nil {
+		return nil, fmt.Errorf("failed to parse saved snippets: %w", err)
+	}
+	for i := range snippets {
+		if snippets[i].Response == nil {
+			snippets[i].Response = &ai.Response{}
+		}
+	}
+	return snippets, nil
+}
+
+// Save snippets to disk
+func Save(snippets []Snippet) error {
+	savedPath, err := GetSavedPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(snippets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(savedPath, data, 0600)
+}
+
+// Find a snippet by name
+func Find(snippets []Snippet, name string) *Snippet {
+	for i := range snippets {
+		if snippets[i].Name == name {
+			return &snippets[i]
+		}
+	}
+	return nil
+}
+
+// Add a snippet, replacing any with the same name
+func Add(snippet Snippet) error {
+	if err := ValidateName(snippet.Name); err != nil {
+		return err
+	}
+	snippets, err := Load()
+	if err != nil {
+		return err
+	}
+	if existing := Find(snippets, snippet.Name); existing != nil {
+		*existing = snippet
+	} else {
+		snippets = append(snippets, snippet)
+	}
+	return Save(snippets)
+}
+
+// Delete a snippet by name
+func Delete(name string) error {
+	snippets, err := Load()
+	if err != nil {
+		return err
+	}
+	for i, snippet := range snippets {
+		if snippet.Name == name {
+			return Save(append(snippets[:i], snippets[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no saved snippet named %s", name)
+}
@@ -0,0 +1,121 @@
+package saved
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"kill-port", false},
+		{"v1.2_backup", false},
+		{"8080", false},
+		{"", true},
+		{"-flag", true},
+		{".hidden", true},
+		{"two words", true},
+		{"a/b", true},
+	}
+
+	for _, test := range tests {
+		if err := ValidateName(test.name); (err != nil) != test.wantErr {
+			t.Errorf("ValidateName(%q) error = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		tags string
+		want []string
+	}{
+		{"net, ports", []string{"net", "ports"}},
+		{"Net Ports,,docker", []string{"net", "ports", "docker"}},
+		{"", nil},
+	}
+
+	for _, test := range tests {
+		if got := ParseTags(test.tags); !slices.Equal(got, test.want) {
+			t.Errorf("ParseTags(%q) = %q, want %q", test.tags, got, test.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Kill Port 8080", "kill-port-8080"},
+		{"  List files (recursively)! ", "list-files-recursively"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := Slugify(test.title); got != test.want {
+			t.Errorf("Slugify(%q) = %q, want %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestAddAndDelete(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	snippet := func(name, command string) Snippet {
+		return Snippet{Name: name, Response: &ai.Response{Commands: []string{command}}}
+	}
+	for _, s := range []Snippet{snippet("a", "one"), snippet("b", "two"), snippet("a", "three")} {
+		if err := Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Add(snippet("bad name", "x")); err == nil {
+		t.Error("Add() with an invalid name returned no error")
+	}
+
+	snippets, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 2 || Find(snippets, "a").Response.Commands[0] != "three" {
+		t.Fatalf("Load() = %+v, want a replaced by the newer snippet", snippets)
+	}
+
+	if err := Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete("a"); err == nil {
+		t.Error("Delete() of a missing snippet returned no error")
+	}
+	snippets, _ = Load()
+	if len(snippets) != 1 || snippets[0].Name != "b" {
+		t.Errorf("Load() after Delete() = %+v", snippets)
+	}
+}
+
+func TestLoadRepairsMissingResponse(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	savedPath, err := GetSavedPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(savedPath, []byte(`[{"name":"broken","question":"q"},{"name":"null","response":null}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	snippets, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, snippet := range snippets {
+		if snippet.Response == nil {
+			t.Errorf("snippet %s has no response", snippet.Name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Server-sent events written to a response
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// Start an event stream. Returns false if the response can't be flushed
+func newEventStream(w http.ResponseWriter) (*eventStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}, true
+}
+
+// Send a named event with a JSON payload
+func (e *eventStream) send(event string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data)
+	e.flusher.Flush()
+}
+
+// Send a comment, which clients ignore, to keep the connection open
+func (e *eventStream) comment(text string) {
+	fmt.Fprintf(e.w, ": %s\n\n", text)
+	e.flusher.Flush()
+}
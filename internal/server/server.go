@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/history"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Limit on request bodies
+const maxBodyBytes = 1024 * 1024
+
+// Interval between keep-alive comments while a streamed answer is pending
+const keepAliveInterval = 10 * time.Second
+
+// Settings for the HTTP server
+type Options struct {
+	Token         string    // Bearer token required on every request
+	Provider      string    // Provider name recorded in history
+	Model         string    // Model name returned with answers and recorded in history
+	RecordHistory bool      // Record answers to questions in the local history
+	Log           io.Writer // Provider errors are written here, nil to disable
+}
+
+// Server exposing ask, explain and history over HTTP for editor plugins
+type Server struct {
+	provider  ai.Provider
+	sysInfo   *system.SystemInfo
+	opts      Options
+	mux       *http.ServeMux
+	historyMu sync.Mutex // History is rewritten on append
+}
+
+// Body of ask and explain requests
+type askRequest struct {
+	Question string `json:"question"`
+	Command  string `json:"command"`          // For explain
+	OS       string `json:"os,omitempty"`     // Answer for another OS, e.g. "macOS"
+	Shell    string `json:"shell,omitempty"`  // Answer for another shell, e.g. "fish"
+	Stream   bool   `json:"stream,omitempty"` // Send progress events while waiting, not the answer in pieces
+}
+
+// History entry as listed by the server
+type historyEntry struct {
+	Question  string          `json:"question"`
+	Provider  string          `json:"provider"`
+	Model     string          `json:"model"`
+	System    *ai.SystemJSON  `json:"system,omitempty"`
+	Response  ai.ResponseJSON `json:"response"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Create a server answering with the provider for the detected system
+func New(provider ai.Provider, sysInfo *system.SystemInfo, opts Options) *Server {
+	s := &Server{
+		provider: provider,
+		sysInfo:  sysInfo,
+		opts:     opts,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /v1/ask", s.handleAsk)
+	s.mux.HandleFunc("POST /v1/explain", s.handleExplain)
+	s.mux.HandleFunc("GET /v1/history", s.handleHistory)
+	return s
+}
+
+// Generate a random bearer token
+func GenerateToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// Serve HTTP on the listener until the context is cancelled, then shut down gracefully
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// Check the bearer token, then route the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !Authorized(r, s.opts.Token) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		WriteError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Check a request's bearer token in constant time
+func Authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func (s *Server) handleAsk(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(body.Question) == "" {
+		WriteError(w, http.StatusBadRequest, "missing question")
+		return
+	}
+	s.answer(w, r, body, &ai.Request{Question: body.Question})
+}
+
+func (s *Server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(body.Command) == "" {
+		WriteError(w, http.StatusBadRequest, "missing command")
+		return
+	}
+	s.answer(w, r, body, &ai.Request{Question: body.Command, Explain: true})
+}
+
+// List history entries newest first, optionally filtered by ?q= and limited by ?limit=
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			WriteError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = parsed
+	}
+	query := strings.ToLower(r.URL.Query().Get("q"))
+
+	entries, err := history.Load()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	listed := []historyEntry{}
+	for i := len(entries) - 1; i >= 0 && len(listed) < limit; i-- {
+		entry := entries[i]
+		if entry.Response == nil {
+			continue
+		}
+		searchable := strings.ToLower(entry.Question + " " + strings.Join(entry.Response.Commands, " "))
+		if query != "" && !strings.Contains(searchable, query) {
+			continue
+		}
+		listed = append(listed, historyEntry{
+			Question:  entry.Question,
+			Provider:  entry.Provider,
+			Model:     entry.Model,
+			System:    systemJSON(entry.SysInfo),
+			Response:  entry.Response.JSON(),
+			Timestamp: entry.Timestamp,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": listed})
+}
+
+// Ask the provider and write the answer, as one JSON object or an event stream
+func (s *Server) answer(w http.ResponseWriter, r *http.Request, body askRequest, request *ai.Request) {
+	request.SysInfo = s.sysInfo.ForTarget(body.OS, body.Shell)
+
+	type result struct {
+		response *ai.Response
+		err      error
+	}
+	results := make(chan result, 1)
+	go func() {
+		response, err := s.provider.Ask(r.Context(), request)
+		results <- result{response, err}
+	}()
+
+	// Providers answer in one piece, so the event stream only reports progress
+	// and keeps the connection alive until the whole answer is sent
+	var events *eventStream
+	if body.Stream {
+		var ok bool
+		if events, ok = newEventStream(w); !ok {
+			WriteError(w, http.StatusInternalServerError, "streaming is not supported")
+			return
+		}
+		events.send("status", map[string]string{"status": "thinking"})
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-keepAlive.C:
+			if events != nil {
+				events.comment("keep-alive")
+			}
+			continue
+		case <-r.Context().Done():
+			return
+		case res := <-results:
+			// Provider errors can include configuration or API keys, so
+			// clients only get a generic message
+			if res.err != nil {
+				if s.opts.Log != nil {
+					fmt.Fprintf(s.opts.Log, "%s %s: %v\n", time.Now().Format(time.DateTime), r.URL.Path, res.err)
+				}
+				if events != nil {
+					events.send("error", map[string]string{"error": "provider request failed"})
+					return
+				}
+				WriteError(w, http.StatusBadGateway, "provider request failed")
+				return
+			}
+
+			if !request.Explain {
+				s.recordHistory(request, res.response)
+			}
+			output := ai.NewAnswerJSON(request, res.response, s.provider.GetName(), s.opts.Model)
+			if events != nil {
+				events.send("answer", output)
+				events.send("done", map[string]string{})
+				return
+			}
+			writeJSON(w, http.StatusOK, output)
+			return
+		}
+	}
+}
+
+// Record an answer in the local history, as the UI does. Cached answers
+// were recorded when first asked
+func (s *Server) recordHistory(request *ai.Request, response *ai.Response) {
+	if !s.opts.RecordHistory || response.Cached || len(response.Commands) == 0 {
+		return
+	}
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	// A failed write only loses the history entry
+	history.Append(history.Entry{
+		Question:  request.Question,
+		SysInfo:   request.SysInfo,
+		Provider:  s.opts.Provider,
+		Model:     s.opts.Model,
+		Response:  response,
+		Timestamp: time.Now(),
+	})
+}
+
+// Decode a JSON request body, writing an error response if it is invalid
+func readRequest(w http.ResponseWriter, r *http.Request) (askRequest, bool) {
+	var body askRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err := decoder.Decode(&body); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		} else {
+			WriteError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		}
+		return body, false
+	}
+	return body, true
+}
+
+// Convert stored system details, nil if an entry has none
+func systemJSON(sysInfo *system.SystemInfo) *ai.SystemJSON {
+	if sysInfo == nil {
+		return nil
+	}
+	converted := ai.NewSystemJSON(sysInfo)
+	return &converted
+}
+
+// Write a value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+// Write an error as a JSON response
+func WriteError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/history"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+const testToken = "test-token"
+
+// Provider that answers with the question, or fails with err
+type fakeProvider struct {
+	err error
+}
+
+func (p *fakeProvider) Ask(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ai.Response{Title: "answer", Commands: []string{"echo " + req.Question + " in " + req.SysInfo.Shell}}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "test"
+}
+
+func newTestServer(t *testing.T, provider ai.Provider, log *bytes.Buffer) *httptest.Server {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	sysInfo := &system.SystemInfo{OS: "linux", OSName: "Ubuntu", Shell: "bash"}
+	opts := Options{Token: testToken, Provider: "test", Model: "m", RecordHistory: true}
+	if log != nil {
+		opts.Log = log
+	}
+	server := httptest.NewServer(New(provider, sysInfo, opts))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func request(t *testing.T, server *httptest.Server, method, path, body, token string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var data bytes.Buffer
+	data.ReadFrom(res.Body)
+	return res.StatusCode, data.String()
+}
+
+func TestAuthorization(t *testing.T) {
+	server := newTestServer(t, &fakeProvider{}, nil)
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + testToken, http.StatusUnauthorized},
+		{"valid", "Bearer " + testToken, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/history", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != test.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, test.wantStatus)
+			}
+			if test.wantStatus == http.StatusUnauthorized && res.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	server := newTestServer(t, &fakeProvider{}, nil)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{"ask", http.MethodPost, "/v1/ask", `{"question": "list files"}`, http.StatusOK, "echo list files in bash"},
+		{"ask for another shell", http.MethodPost, "/v1/ask", `{"question": "list files", "shell": "fish"}`, http.StatusOK, "echo list files in fish"},
+		{"ask without question", http.MethodPost, "/v1/ask", `{"question": " "}`, http.StatusBadRequest, "missing question"},
+		{"invalid JSON", http.MethodPost, "/v1/ask", `{`, http.StatusBadRequest, "invalid JSON body"},
+		{"explain", http.MethodPost, "/v1/explain", `{"command": "ls -la"}`, http.StatusOK, "echo ls -la in bash"},
+		{"explain without command", http.MethodPost, "/v1/explain", `{}`, http.StatusBadRequest, "missing command"},
+		{"history limit", http.MethodGet, "/v1/history?limit=0", "", http.StatusBadRequest, "limit must be a positive number"},
+		{"wrong method", http.MethodGet, "/v1/ask", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := request(t, server, test.method, test.path, test.body, testToken)
+			if status != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", status, test.wantStatus, body)
+			}
+			if !strings.Contains(body, test.want) {
+				t.Errorf("body = %s, want it to contain %q", body, test.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	server := newTestServer(t, &fakeProvider{}, nil)
+	for _, question := range []string{"list ports", "list files"} {
+		request(t, server, http.MethodPost, "/v1/ask", `{"question": "`+question+`"}`, testToken)
+	}
+	// Explanations are not recorded
+	request(t, server, http.MethodPost, "/v1/explain", `{"command": "ls"}`, testToken)
+
+	entries, err := history.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Provider != "test" || entries[0].Model != "m" {
+		t.Fatalf("history = %+v, want the two questions", entries)
+	}
+
+	_, body := request(t, server, http.MethodGet, "/v1/history?q=ports", "", testToken)
+	if !strings.Contains(body, "list ports") || strings.Contains(body, "list files") {
+		t.Errorf("filtered history = %s", body)
+	}
+	_, body = request(t, server, http.MethodGet, "/v1/history?limit=1", "", testToken)
+	if !strings.Contains(body, "list files") || strings.Contains(body, "list ports") {
+		t.Errorf("limited history = %s, want only the newest entry", body)
+	}
+}
+
+func TestProviderErrorIsGeneric(t *testing.T) {
+	var log bytes.Buffer
+	server := newTestServer(t, &fakeProvider{err: errors.New("401 invalid key sk-secret")}, &log)
+
+	status, body := request(t, server, http.MethodPost, "/v1/ask", `{"question": "q"}`, testToken)
+	if status != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", status, http.StatusBadGateway)
+	}
+	if strings.Contains(body, "sk-secret") || !strings.Contains(body, "provider request failed") {
+		t.Errorf("body = %s, want only a generic error", body)
+	}
+
+	_, body = request(t, server, http.MethodPost, "/v1/ask", `{"question": "q", "stream": true}`, testToken)
+	if strings.Contains(body, "sk-secret") || !strings.Contains(body, "event: error") {
+		t.Errorf("stream = %s, want only a generic error event", body)
+	}
+
+	if strings.Count(log.String(), "sk-secret") != 2 {
+		t.Errorf("log = %q, want both provider errors", log.String())
+	}
+}
+
+func TestStream(t *testing.T) {
+	server := newTestServer(t, &fakeProvider{}, nil)
+
+	status, body := request(t, server, http.MethodPost, "/v1/ask", `{"question": "list files", "stream": true}`, testToken)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	thinking, answer, done := strings.Index(body, "event: status"), strings.Index(body, "event: answer"), strings.Index(body, "event: done")
+	if thinking < 0 || answer < thinking || done < answer {
+		t.Errorf("events out of order:\n%s", body)
+	}
+	if !strings.Contains(body, "echo list files in bash") {
+		t.Errorf("answer missing from stream:\n%s", body)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if Authorized(req, testToken) {
+		t.Error("request without a header authorized")
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken+"x")
+	if Authorized(req, testToken) {
+		t.Error("token with a suffix authorized")
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if !Authorized(req, testToken) {
+		t.Error("valid token rejected")
+	}
+}
@@ -0,0 +1,200 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Commands that run another command given as their arguments
+var wrapperCommands = []string{
+	"sudo", "doas", "env", "time", "nohup", "nice", "ionice", "xargs", "exec",
+	"command", "builtin", "timeout", "watch", "stdbuf", "strace", "caffeinate",
+	// fish
+	"and", "or", "not",
+}
+
+// Wrapper options that take a separate value, e.g. "sudo -u root"
+var wrapperValueFlags = map[string][]string{
+	"sudo":    {"-u", "-g", "-C", "-D", "-h", "-p", "-U", "-r", "-t"},
+	"doas":    {"-u", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"nice":    {"-n"},
+	"ionice":  {"-c", "-n", "-p"},
+	"xargs":   {"-I", "-L", "-n", "-P", "-s", "-d", "-E", "-a"},
+	"timeout": {"-s", "-k"},
+	"watch":   {"-n", "-d"},
+	"stdbuf":  {"-i", "-o", "-e"},
+	"strace":  {"-e", "-o", "-p", "-s", "-u"},
+}
+
+// Wrappers whose first positional argument is not the command, e.g. "timeout 5 cmd"
+var wrapperPositionalArgs = map[string]int{
+	"timeout": 1,
+}
+
+// Shell builtins and keywords that are never on PATH
+var shellBuiltins = []string{
+	".", ":", "[", "[[", "alias", "bg", "bind", "break", "builtin", "caller", "cd",
+	"command", "compgen", "complete", "continue", "declare", "dirs", "disown", "echo",
+	"enable", "eval", "exec", "exit", "export", "false", "fc", "fg", "getopts", "hash",
+	"help", "history", "jobs", "kill", "let", "local", "logout", "mapfile", "popd",
+	"printf", "pushd", "pwd", "read", "readarray", "readonly", "return", "set", "shift",
+	"shopt", "source", "suspend", "test", "times", "trap", "true", "type", "typeset",
+	"ulimit", "umask", "unalias", "unset", "wait",
+	// zsh
+	"autoload", "bindkey", "emulate", "noglob", "print", "rehash", "setopt", "unsetopt",
+	"whence", "where", "which", "zmodload", "zle", "zstyle",
+	// fish
+	"abbr", "and", "argparse", "begin", "contains", "count", "end", "functions", "math",
+	"not", "or", "status", "string",
+}
+
+// Extract the executables a command or script invokes, including those after
+// pipes, && and ||, in subshells, and behind wrappers such as sudo and xargs.
+// Builtins, functions defined in the script, and dynamic names are skipped
+func Executables(command, shellName string) []string {
+	if !IsPOSIXLike(shellName) {
+		if shellName == "fish" {
+			return fishExecutables(command)
+		}
+		return nil
+	}
+
+	file, err := parse(command, shellName)
+	if err != nil {
+		return nil
+	}
+
+	// Functions defined in the script are not executables
+	var functions []string
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if fn, ok := node.(*syntax.FuncDecl); ok {
+			functions = append(functions, fn.Name.Value)
+		}
+		return true
+	})
+
+	var executables []string
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+		var args []string
+		for _, word := range call.Args {
+			// Non-literal words such as $cmd end the static part of the command
+			lit := word.Lit()
+			if lit == "" {
+				break
+			}
+			args = append(args, lit)
+		}
+		for _, name := range commandNames(args) {
+			if !slices.Contains(functions, name) && !slices.Contains(executables, name) {
+				executables = append(executables, name)
+			}
+		}
+		return true
+	})
+	return executables
+}
+
+// Find the executables a command invokes that are not on PATH
+func MissingExecutables(command, shellName string) []string {
+	var missing []string
+	for _, name := range Executables(command, shellName) {
+		if !executableExists(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Resolve the command names in a simple command's literal arguments,
+// following wrappers to the command they run
+func commandNames(args []string) []string {
+	var names []string
+	for len(args) > 0 {
+		name := args[0]
+		if slices.Contains(shellBuiltins, name) && !slices.Contains(wrapperCommands, name) {
+			return names
+		}
+		if !slices.Contains(wrapperCommands, name) {
+			return append(names, name)
+		}
+
+		// Wrappers are often real executables themselves
+		if !slices.Contains(shellBuiltins, name) {
+			names = append(names, name)
+		}
+		args = skipWrapperArgs(name, args[1:])
+	}
+	return names
+}
+
+// Skip a wrapper's options, assignments and positional arguments,
+// returning the wrapped command and its arguments
+func skipWrapperArgs(wrapper string, args []string) []string {
+	positional := wrapperPositionalArgs[wrapper]
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			return args[1:]
+		case wrapper == "command" && (arg == "-v" || arg == "-V"):
+			// Looks the command up rather than running it
+			return nil
+		case strings.HasPrefix(arg, "-"):
+			args = args[1:]
+			if slices.Contains(wrapperValueFlags[wrapper], arg) && len(args) > 0 {
+				args = args[1:]
+			}
+		case wrapper == "env" && strings.Contains(arg, "="):
+			args = args[1:]
+		case positional > 0:
+			positional--
+			args = args[1:]
+		default:
+			return args
+		}
+	}
+	return nil
+}
+
+// Extract executables from fish commands, which the parser does not support.
+// Splits on pipes and command separators and takes the first word of each
+func fishExecutables(command string) []string {
+	replacer := strings.NewReplacer("|", "\n", ";", "\n", "&&", "\n", "||", "\n", "(", "\n", ")", "\n")
+	var executables []string
+	for _, segment := range strings.Split(replacer.Replace(command), "\n") {
+		fields := strings.Fields(segment)
+		if len(fields) == 0 || strings.ContainsAny(fields[0], "$'\"") {
+			continue
+		}
+		for _, name := range commandNames(fields) {
+			if !slices.Contains(executables, name) {
+				executables = append(executables, name)
+			}
+		}
+	}
+	return executables
+}
+
+// Check if a command name resolves to an executable.
+// Relative paths such as ./script.sh are assumed to exist
+func executableExists(name string) bool {
+	if strings.Contains(name, "/") {
+		if !filepath.IsAbs(name) {
+			return true
+		}
+		_, err := os.Stat(name)
+		return err == nil
+	}
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExecutables(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		shellName string
+		want      []string
+	}{
+		{"simple", "ls -la", "bash", []string{"ls"}},
+		{"pipeline", "ps aux | grep foo | awk '{print $2}'", "bash", []string{"ps", "grep", "awk"}},
+		{"and or", "make && ./run.sh || echo failed", "bash", []string{"make", "./run.sh"}},
+		{"subshell", "echo $(date +%s)", "bash", []string{"date"}},
+		{"sudo with user", "sudo -u www-data php artisan", "bash", []string{"sudo", "php"}},
+		{"env assignments", "env FOO=1 BAR=2 node app.js", "bash", []string{"env", "node"}},
+		{"timeout duration", "timeout 5 curl example.com", "bash", []string{"timeout", "curl"}},
+		{"xargs", "find . -name '*.go' | xargs -n 1 gofmt -l", "bash", []string{"find", "xargs", "gofmt"}},
+		{"command -v", "command -v jq", "bash", nil},
+		{"builtins only", "cd /tmp && export X=1", "bash", nil},
+		{"function", "greet() { printf hi; }; greet", "bash", nil},
+		{"dynamic name", "$EDITOR file.txt", "bash", nil},
+		{"duplicates", "git add . && git commit", "bash", []string{"git"}},
+		{"syntax error", "if then", "bash", nil},
+		{"fish", "ls | string upper; and rg foo", "fish", []string{"ls", "rg"}},
+		{"unsupported shell", "Get-ChildItem", "powershell", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Executables(test.command, test.shellName)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Executables(%q) = %q, want %q", test.command, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMissingExecutables(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	got := MissingExecutables("definitely-not-installed --flag && ./local.sh && /nonexistent/bin/tool", "bash")
+	want := []string{"definitely-not-installed", "/nonexistent/bin/tool"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MissingExecutables() = %q, want %q", got, want)
+	}
+}
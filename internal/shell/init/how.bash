@@ -0,0 +1,15 @@
+# how shell integration for bash
+# Add to ~/.bashrc:  eval "$(how --init bash)"
+# Type a question, press Ctrl+G, and the answer replaces it on the command line.
+
+__how_widget() {
+    [[ -z "$READLINE_LINE" ]] && return
+    local result
+    result="$(how --print -- "$READLINE_LINE")"
+    if [[ -n "$result" ]]; then
+        READLINE_LINE="$result"
+        READLINE_POINT=${#READLINE_LINE}
+    fi
+}
+
+bind -x '"\C-g": __how_widget'
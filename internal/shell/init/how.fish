@@ -0,0 +1,16 @@
+# how shell integration for fish
+# Add to ~/.config/fish/config.fish:  how --init fish | source
+# Type a question, press Ctrl+G, and the answer replaces it on the command line.
+
+function __how_widget
+    set -l question (commandline)
+    test -z "$question"; and return
+    set -l result (how --print -- "$question" | string collect)
+    if test -n "$result"
+        commandline --replace -- $result
+        commandline --cursor (string length -- $result)
+    end
+    commandline --function repaint
+end
+
+bind \cg __how_widget
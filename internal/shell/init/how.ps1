@@ -0,0 +1,16 @@
+# how shell integration for PowerShell
+# Add to $PROFILE:  Invoke-Expression (& how --init pwsh | Out-String)
+# Type a question, press Ctrl+G, and the answer replaces it on the command line.
+
+Set-PSReadLineKeyHandler -Chord 'Ctrl+g' -BriefDescription 'how' -Description 'Replace the question on the command line with a command from how' -ScriptBlock {
+    $line = $null
+    $cursor = $null
+    [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+    if ([string]::IsNullOrWhiteSpace($line)) { return }
+
+    $result = (& how --print -- $line) -join "`n"
+    if ($result) {
+        [Microsoft.PowerShell.PSConsoleReadLine]::Replace(0, $line.Length, $result)
+    }
+    [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+}
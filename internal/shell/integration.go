@@ -0,0 +1,38 @@
+package shell
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed init
+var initScripts embed.FS
+
+// Shells with an integration script, and the script for each
+var initScriptFiles = map[string]string{
+	"bash":       "how.bash",
+	"zsh":        "how.zsh",
+	"fish":       "how.fish",
+	"nu":         "how.nu",
+	"pwsh":       "how.ps1",
+	"powershell": "how.ps1",
+}
+
+// Return the names of shells with an integration script
+func GetInitShells() []string {
+	return []string{"bash", "zsh", "fish", "nu", "pwsh"}
+}
+
+// Return the script that binds how to a key in a shell
+func InitScript(shellName string) (string, error) {
+	file, ok := initScriptFiles[shellName]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shellName, strings.Join(GetInitShells(), ", "))
+	}
+	script, err := initScripts.ReadFile("init/" + file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s integration: %w", shellName, err)
+	}
+	return string(script), nil
+}
@@ -0,0 +1,27 @@
+package shell
+
+import "testing"
+
+func TestInitScript(t *testing.T) {
+	for _, shellName := range append(GetInitShells(), "powershell") {
+		t.Run(shellName, func(t *testing.T) {
+			script, err := InitScript(shellName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if script == "" {
+				t.Fatal("empty script")
+			}
+			// Scripts the built-in parser understands must parse
+			if shellName == "bash" || shellName == "zsh" {
+				if err := Validate(script, shellName); err != nil {
+					t.Errorf("script does not parse: %v", err)
+				}
+			}
+		})
+	}
+
+	if _, err := InitScript("tcsh"); err == nil {
+		t.Error("InitScript() of an unsupported shell returned no error")
+	}
+}
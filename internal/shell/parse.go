@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Check if commands for a shell can be parsed as POSIX-like shell syntax.
+// fish, PowerShell and cmd use their own syntax
+func IsPOSIXLike(shellName string) bool {
+	switch shellName {
+	case "fish", "powershell", "pwsh", "cmd", "nu":
+		return false
+	}
+	return true
+}
+
+// Pick the parser language for a shell
+func variant(shellName string) syntax.LangVariant {
+	switch shellName {
+	case "sh", "dash", "ash":
+		return syntax.LangPOSIX
+	case "mksh", "ksh":
+		return syntax.LangMirBSDKorn
+	case "zsh":
+		return syntax.LangZsh
+	default:
+		return syntax.LangBash
+	}
+}
+
+// Parse a command or script for a POSIX-like shell
+func parse(command, shellName string) (*syntax.File, error) {
+	parser := syntax.NewParser(syntax.Variant(variant(shellName)))
+	return parser.Parse(strings.NewReader(command), "")
+}
@@ -0,0 +1,370 @@
+package shell
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// How much harm a command can do, from least to most
+type Risk int
+
+const (
+	RiskSafe Risk = iota
+	RiskModifiesFiles
+	RiskPrivileged
+	RiskDestructive
+	RiskRemoteCode
+)
+
+// Risk level of a command and why
+type Assessment struct {
+	Risk   Risk   `json:"level"`
+	Reason string `json:"reason,omitempty"` // Empty for safe commands
+}
+
+// Check if the command should be confirmed before it is copied or run
+func (a Assessment) IsHighRisk() bool {
+	return a.Risk >= RiskDestructive
+}
+
+// Display name for a risk level
+func (r Risk) String() string {
+	switch r {
+	case RiskModifiesFiles:
+		return "modifies files"
+	case RiskPrivileged:
+		return "privileged"
+	case RiskDestructive:
+		return "destructive"
+	case RiskRemoteCode:
+		return "runs remote code"
+	default:
+		return "safe"
+	}
+}
+
+// Encode the level by name, e.g. "destructive"
+func (r Risk) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Commands that run other commands as another user
+var privilegeCommands = []string{"sudo", "doas", "su", "pkexec", "runas"}
+
+// Commands that download content
+var downloadCommands = []string{"curl", "wget", "fetch", "invoke-webrequest", "iwr", "invoke-restmethod", "irm"}
+
+// Commands that run code read from stdin or an argument
+var interpreterCommands = []string{
+	"sh", "bash", "zsh", "dash", "ksh", "fish", "python", "python3", "perl", "ruby", "node",
+	"php", "pwsh", "powershell", "iex", "invoke-expression", "source", ".", "eval",
+}
+
+// Commands that format, partition or wipe disks
+var diskCommands = []string{"mkfs", "wipefs", "fdisk", "sfdisk", "gdisk", "parted", "shred", "format-volume", "clear-disk", "diskpart"}
+
+// Commands that change files or their metadata
+var fileCommands = []string{
+	"rm", "rmdir", "mv", "cp", "ln", "touch", "mkdir", "truncate", "chmod", "chown", "chgrp",
+	"tee", "install", "unlink", "rsync", "del", "erase", "rd", "move", "copy", "ren",
+	"remove-item", "move-item", "copy-item", "set-content", "new-item", "out-file",
+}
+
+// Paths whose loss would break the system or a home directory
+var criticalPaths = []string{"/", "/*", "~", "~/", "~/*", "$HOME", "$HOME/*", "/etc", "/usr", "/var", "/boot", "/home", "c:\\", "c:\\*"}
+
+// Classify the riskiest thing a command or script does
+func Analyze(command, shellName string) Assessment {
+	calls, redirects := simpleCommands(command, shellName)
+
+	assessment := Assessment{Risk: RiskSafe}
+	raise := func(risk Risk, reason string) {
+		if risk > assessment.Risk {
+			assessment = Assessment{Risk: risk, Reason: reason}
+		}
+	}
+
+	var downloads, interprets bool
+	for _, args := range calls {
+		args, privileged := unwrap(args)
+		if privileged {
+			raise(RiskPrivileged, "Runs with elevated privileges")
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToLower(path.Base(args[0]))
+		if slices.Contains(downloadCommands, name) {
+			downloads = true
+		}
+		if slices.Contains(interpreterCommands, name) {
+			interprets = true
+		}
+		if risk, reason := analyzeCall(name, args[1:]); risk != RiskSafe {
+			raise(risk, reason)
+		}
+	}
+
+	for _, target := range redirects {
+		switch {
+		case isDiskDevice(target):
+			raise(RiskDestructive, "Writes directly to a disk device")
+		case target == "":
+			raise(RiskModifiesFiles, "Writes to a file")
+		case target != "/dev/null" && target != "/dev/stdout" && target != "/dev/stderr":
+			raise(RiskModifiesFiles, "Writes to "+target)
+		}
+	}
+
+	if downloads && interprets {
+		raise(RiskRemoteCode, "Downloads and runs a remote script")
+	}
+	return assessment
+}
+
+// Classify the riskiest of several commands
+func AnalyzeAll(commands []string, shellName string) Assessment {
+	assessment := Assessment{Risk: RiskSafe}
+	for _, command := range commands {
+		if a := Analyze(command, shellName); a.Risk > assessment.Risk {
+			assessment = a
+		}
+	}
+	return assessment
+}
+
+// Classify a single command by name and arguments
+func analyzeCall(name string, args []string) (Risk, string) {
+	flags := shortFlags(args)
+	switch {
+	case name == "rm" || name == "remove-item" || name == "del" || name == "rd" || name == "rmdir":
+		recursive := flags["r"] || flags["R"] || hasArg(args, "--recursive", "-recurse", "/s")
+		force := flags["f"] || hasArg(args, "--force", "-force", "/q")
+		if targetsCriticalPath(args) {
+			return RiskDestructive, "Deletes a system or home directory"
+		}
+		if recursive && force {
+			return RiskDestructive, "Recursively force-deletes files"
+		}
+		return RiskModifiesFiles, "Deletes files"
+
+	case name == "dd":
+		for _, arg := range args {
+			if target, ok := strings.CutPrefix(arg, "of="); ok && isDiskDevice(target) {
+				return RiskDestructive, "Writes directly to a disk device"
+			}
+		}
+		return RiskModifiesFiles, "Writes files with dd"
+
+	case slices.Contains(diskCommands, name) || strings.HasPrefix(name, "mkfs."):
+		return RiskDestructive, "Formats or wipes a disk"
+
+	case name == "chmod" || name == "chown" || name == "chgrp":
+		recursive := flags["R"] || hasArg(args, "--recursive")
+		if recursive && (targetsCriticalPath(args) || hasArg(args, "777", "a+rwx")) {
+			return RiskDestructive, "Recursively changes ownership or permissions"
+		}
+		return RiskModifiesFiles, "Changes file ownership or permissions"
+
+	case name == "git" && len(args) > 0:
+		return analyzeGit(args)
+
+	case name == "find":
+		if hasArg(args, "-delete") || (hasArg(args, "-exec", "-execdir") && hasArg(args, "rm")) {
+			return RiskDestructive, "Deletes every file find matches"
+		}
+
+	case name == "sed" || name == "perl":
+		if flags["i"] || hasArgPrefix(args, "--in-place", "-i") {
+			return RiskModifiesFiles, "Edits files in place"
+		}
+
+	case slices.Contains(fileCommands, name):
+		return RiskModifiesFiles, "Changes files"
+	}
+	return RiskSafe, ""
+}
+
+// Classify git subcommands that discard work or rewrite history
+func analyzeGit(args []string) (Risk, string) {
+	subcommand := args[0]
+	args = args[1:]
+	flags := shortFlags(args)
+	switch subcommand {
+	case "push":
+		if flags["f"] || hasArgPrefix(args, "--force") || slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "+") }) {
+			return RiskDestructive, "Force-pushes, overwriting remote history"
+		}
+		if hasArg(args, "--delete") || flags["d"] {
+			return RiskDestructive, "Deletes a remote branch"
+		}
+	case "reset":
+		if hasArg(args, "--hard") {
+			return RiskDestructive, "Discards uncommitted changes"
+		}
+	case "clean":
+		if flags["f"] || hasArg(args, "--force") {
+			return RiskDestructive, "Deletes untracked files"
+		}
+	case "checkout", "restore":
+		if hasArg(args, ".", "--", "-f", "--force") {
+			return RiskDestructive, "Discards uncommitted changes"
+		}
+	case "branch":
+		if flags["D"] {
+			return RiskDestructive, "Force-deletes a branch"
+		}
+	case "filter-branch", "filter-repo":
+		return RiskDestructive, "Rewrites repository history"
+	}
+	return RiskSafe, ""
+}
+
+// Split a command into simple commands with their literal arguments, and the
+// targets of output redirections. Dynamic words are kept as empty strings
+func simpleCommands(command, shellName string) ([][]string, []string) {
+	if !IsPOSIXLike(shellName) {
+		return splitCommands(command), nil
+	}
+	file, err := parse(command, shellName)
+	if err != nil {
+		return splitCommands(command), nil
+	}
+
+	var calls [][]string
+	var redirects []string
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.CallExpr:
+			var args []string
+			for _, word := range node.Args {
+				args = append(args, wordText(word))
+			}
+			calls = append(calls, args)
+		case *syntax.Redirect:
+			switch node.Op {
+			case syntax.RdrOut, syntax.AppOut, syntax.RdrAll, syntax.AppAll, syntax.ClbOut:
+				if node.Word != nil {
+					redirects = append(redirects, wordText(node.Word))
+				}
+			}
+		}
+		return true
+	})
+	return calls, redirects
+}
+
+// Get the text of a word made of literals and quotes, with $HOME and
+// ${HOME} kept as "$HOME". Returns "" for other expansions
+func wordText(word *syntax.Word) string {
+	var text strings.Builder
+	if !appendWordParts(&text, word.Parts) {
+		return ""
+	}
+	return text.String()
+}
+
+func appendWordParts(text *strings.Builder, parts []syntax.WordPart) bool {
+	for _, part := range parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			text.WriteString(part.Value)
+		case *syntax.SglQuoted:
+			text.WriteString(part.Value)
+		case *syntax.DblQuoted:
+			if !appendWordParts(text, part.Parts) {
+				return false
+			}
+		case *syntax.ParamExp:
+			if !isHomeParam(part) {
+				return false
+			}
+			text.WriteString("$HOME")
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Check for a plain $HOME or ${HOME} without modifiers
+func isHomeParam(param *syntax.ParamExp) bool {
+	var printed strings.Builder
+	if err := syntax.NewPrinter().Print(&printed, param); err != nil {
+		return false
+	}
+	return printed.String() == "$HOME" || printed.String() == "${HOME}"
+}
+
+// Split commands for shells the parser does not support on pipes and separators
+func splitCommands(command string) [][]string {
+	replacer := strings.NewReplacer("|", "\n", ";", "\n", "&&", "\n", "||", "\n", "(", "\n", ")", "\n")
+	var calls [][]string
+	for _, segment := range strings.Split(replacer.Replace(command), "\n") {
+		if fields := strings.Fields(segment); len(fields) > 0 {
+			calls = append(calls, fields)
+		}
+	}
+	return calls
+}
+
+// Strip wrappers such as sudo and xargs, reporting if any elevate privileges
+func unwrap(args []string) ([]string, bool) {
+	var privileged bool
+	for len(args) > 0 && (slices.Contains(wrapperCommands, args[0]) || slices.Contains(privilegeCommands, args[0])) {
+		if slices.Contains(privilegeCommands, args[0]) {
+			privileged = true
+		}
+		args = skipWrapperArgs(args[0], args[1:])
+	}
+	return args, privileged
+}
+
+// Collect single-letter flags, including combined ones such as -rf
+func shortFlags(args []string) map[string]bool {
+	flags := map[string]bool{}
+	for _, arg := range args {
+		if len(arg) < 2 || arg[0] != '-' || arg[1] == '-' {
+			continue
+		}
+		for _, r := range arg[1:] {
+			flags[string(r)] = true
+		}
+	}
+	return flags
+}
+
+// Check if any argument matches one of values, ignoring case
+func hasArg(args []string, values ...string) bool {
+	return slices.ContainsFunc(args, func(arg string) bool {
+		return slices.ContainsFunc(values, func(value string) bool { return strings.EqualFold(arg, value) })
+	})
+}
+
+// Check if any argument starts with one of prefixes
+func hasArgPrefix(args []string, prefixes ...string) bool {
+	return slices.ContainsFunc(args, func(arg string) bool {
+		return slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(arg, prefix) })
+	})
+}
+
+// Check if any argument is a critical system or home path
+func targetsCriticalPath(args []string) bool {
+	return slices.ContainsFunc(args, func(arg string) bool {
+		return arg == "/" || hasArg(criticalPaths, strings.TrimSuffix(arg, "/"))
+	})
+}
+
+// Check if a path is a block device such as /dev/sda or /dev/nvme0n1
+func isDiskDevice(target string) bool {
+	for _, prefix := range []string{"/dev/sd", "/dev/hd", "/dev/vd", "/dev/nvme", "/dev/mmcblk", "/dev/disk", "/dev/rdisk"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+	return false
+}
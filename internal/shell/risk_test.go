@@ -0,0 +1,102 @@
+package shell
+
+import "testing"
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		shellName string
+		want      Risk
+	}{
+		{"listing", "ls -la", "bash", RiskSafe},
+		{"pipeline", "ps aux | grep foo", "bash", RiskSafe},
+		{"delete file", "rm notes.txt", "bash", RiskModifiesFiles},
+		{"recursive force delete", "rm -rf build", "bash", RiskDestructive},
+		{"delete root", "rm -r /", "bash", RiskDestructive},
+		{"quoted root", `rm -rf "/"`, "bash", RiskDestructive},
+		{"quoted home", `rm -rf "$HOME"`, "bash", RiskDestructive},
+		{"home with slash", "rm -rf $HOME/", "bash", RiskDestructive},
+		{"braced home", "rm -r ${HOME}", "bash", RiskDestructive},
+		{"home glob", "rm -r $HOME/*", "bash", RiskDestructive},
+		{"sudo quoted tilde", `sudo rm -r "~"`, "bash", RiskDestructive},
+		{"single-quoted etc", "rm -r '/etc'", "bash", RiskDestructive},
+		{"home subdirectory", "rm -r $HOME/tmp", "bash", RiskModifiesFiles},
+		{"other variable", "rm -r $DIR", "bash", RiskModifiesFiles},
+		{"sudo", "sudo apt update", "bash", RiskPrivileged},
+		{"redirect", "echo hi > out.txt", "bash", RiskModifiesFiles},
+		{"redirect to null", "make 2> /dev/null", "bash", RiskSafe},
+		{"disk redirect", "cat image.iso > /dev/sda", "bash", RiskDestructive},
+		{"dd to disk", "dd if=image.iso of=/dev/nvme0n1", "bash", RiskDestructive},
+		{"mkfs", "mkfs.ext4 /dev/sdb1", "bash", RiskDestructive},
+		{"curl to shell", "curl -fsSL https://example.com/install.sh | sh", "bash", RiskRemoteCode},
+		{"curl only", "curl -O https://example.com/file", "bash", RiskSafe},
+		{"find delete", "find . -name '*.tmp' -delete", "bash", RiskDestructive},
+		{"sed in place", "sed -i 's/a/b/' file", "bash", RiskModifiesFiles},
+		{"git force push", "git push --force origin main", "bash", RiskDestructive},
+		{"git reset hard", "git reset --hard HEAD~1", "bash", RiskDestructive},
+		{"git status", "git status", "bash", RiskSafe},
+		{"powershell delete", "Remove-Item -Recurse -Force C:\\", "powershell", RiskDestructive},
+		{"fish recursive delete", "rm -rf ~/", "fish", RiskDestructive},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Analyze(test.command, test.shellName); got.Risk != test.want {
+				t.Errorf("Analyze(%q) = %v (%q), want %v", test.command, got.Risk, got.Reason, test.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeAll(t *testing.T) {
+	got := AnalyzeAll([]string{"ls", "rm -rf /", "sudo ls"}, "bash")
+	if got.Risk != RiskDestructive || !got.IsHighRisk() {
+		t.Errorf("AnalyzeAll() = %+v, want destructive", got)
+	}
+}
+
+func TestAnalyzeCall(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want Risk
+	}{
+		{"rm", []string{"-f", "a"}, RiskModifiesFiles},
+		{"rm", []string{"-r", "-f", "a"}, RiskDestructive},
+		{"chmod", []string{"-R", "777", "."}, RiskDestructive},
+		{"chmod", []string{"644", "file"}, RiskModifiesFiles},
+		{"git", []string{"clean", "-fd"}, RiskDestructive},
+		{"git", []string{"branch", "-d", "old"}, RiskSafe},
+		{"cp", []string{"a", "b"}, RiskModifiesFiles},
+		{"cat", []string{"a"}, RiskSafe},
+	}
+
+	for _, test := range tests {
+		if got, _ := analyzeCall(test.name, test.args); got != test.want {
+			t.Errorf("analyzeCall(%q, %q) = %v, want %v", test.name, test.args, got, test.want)
+		}
+	}
+}
+
+func TestTargetsCriticalPath(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"/"}, true},
+		{[]string{"-rf", "/usr/"}, true},
+		{[]string{"~"}, true},
+		{[]string{"$HOME"}, true},
+		{[]string{`C:\`}, true},
+		{[]string{"/usr/local/bin"}, false},
+		{[]string{"./build"}, false},
+		{[]string{""}, false},
+	}
+
+	for _, test := range tests {
+		if got := targetsCriticalPath(test.args); got != test.want {
+			t.Errorf("targetsCriticalPath(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
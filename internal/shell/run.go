@@ -0,0 +1,86 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Build the process that runs a command in the user's shell. A single-line
+// command is passed to the shell directly, a script is written to an
+// executable temp file that runs with its own shebang or the user's shell.
+// The returned cleanup function removes the script
+func Command(command, shellName, shellPath string) (*exec.Cmd, func(), error) {
+	if shellPath == "" {
+		return nil, nil, fmt.Errorf("no shell found to run the command")
+	}
+	if !strings.Contains(command, "\n") {
+		return exec.Command(shellPath, commandArgs(shellName, command)...), func() {}, nil
+	}
+
+	path, err := writeScript(command, shellName, shellPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.Remove(path) }
+
+	switch shellName {
+	case "powershell", "pwsh":
+		return exec.Command(shellPath, "-NoProfile", "-File", path), cleanup, nil
+	case "cmd":
+		return exec.Command(shellPath, "/C", path), cleanup, nil
+	default:
+		return exec.Command(path), cleanup, nil
+	}
+}
+
+// Arguments that make a shell run a single command
+func commandArgs(shellName, command string) []string {
+	switch shellName {
+	case "powershell", "pwsh":
+		return []string{"-NoProfile", "-Command", command}
+	case "cmd":
+		return []string{"/C", command}
+	default:
+		return []string{"-c", command}
+	}
+}
+
+// Write a script to a temp file with the shell's extension. Scripts for
+// other shells are made executable, with a shebang for shellPath unless
+// they have their own, e.g. "#!/usr/bin/env python3"
+func writeScript(script, shellName, shellPath string) (string, error) {
+	extension := ".sh"
+	switch shellName {
+	case "powershell", "pwsh":
+		extension = ".ps1"
+	case "cmd":
+		extension = ".cmd"
+	case "fish":
+		extension = ".fish"
+	}
+
+	file, err := os.CreateTemp("", "how-*"+extension)
+	if err != nil {
+		return "", fmt.Errorf("failed to create script: %w", err)
+	}
+	defer file.Close()
+
+	executable := shellName != "powershell" && shellName != "pwsh" && shellName != "cmd"
+	if executable && !strings.HasPrefix(script, "#!") {
+		script = "#!" + shellPath + "\n" + script
+	}
+
+	if _, err := file.WriteString(script + "\n"); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write script: %w", err)
+	}
+	if executable {
+		if err := file.Chmod(0700); err != nil {
+			os.Remove(file.Name())
+			return "", fmt.Errorf("failed to make script executable: %w", err)
+		}
+	}
+	return file.Name(), nil
+}
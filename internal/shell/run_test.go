@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs POSIX shell scripts")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not installed")
+	}
+
+	tests := []struct {
+		name        string
+		command     string
+		wantShebang string
+		wantOutput  string
+	}{
+		{"single line", "echo one", "", "one\n"},
+		{"script", "echo one\necho two", "#!" + sh, "one\ntwo\n"},
+		{"own shebang", "#!/usr/bin/env sh\necho three\necho four", "#!/usr/bin/env sh", "three\nfour\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, cleanup, err := Command(test.command, "sh", sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+
+			if test.wantShebang != "" {
+				script, err := os.ReadFile(cmd.Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if first, _, _ := strings.Cut(string(script), "\n"); first != test.wantShebang {
+					t.Errorf("shebang = %q, want %q", first, test.wantShebang)
+				}
+				if strings.Count(string(script), "#!") != 1 {
+					t.Errorf("script has more than one shebang:\n%s", script)
+				}
+			}
+
+			var stdout bytes.Buffer
+			cmd.Stdout = &stdout
+			if err := cmd.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if stdout.String() != test.wantOutput {
+				t.Errorf("output = %q, want %q", stdout.String(), test.wantOutput)
+			}
+		})
+	}
+}
+
+func TestCommandCleanup(t *testing.T) {
+	cmd, cleanup, err := Command("echo one\necho two", "bash", "/bin/bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+	if _, err := os.Stat(cmd.Path); !os.IsNotExist(err) {
+		t.Errorf("script %s not removed", cmd.Path)
+	}
+}
+
+func TestCommandWithoutShell(t *testing.T) {
+	if _, _, err := Command("ls", "bash", ""); err == nil {
+		t.Error("Command() without a shell path returned no error")
+	}
+}
@@ -0,0 +1,46 @@
+package shell
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Check a command or script for syntax errors in the given shell.
+// POSIX-like shells use the built-in parser, fish uses "fish --no-execute"
+// when it is installed. Other shells are not checked
+func Validate(command, shellName string) error {
+	if IsPOSIXLike(shellName) {
+		_, err := parse(command, shellName)
+		return err
+	}
+	if shellName == "fish" {
+		return validateFish(command)
+	}
+	return nil
+}
+
+// Check fish syntax without running the command
+func validateFish(command string) error {
+	path, err := exec.LookPath("fish")
+	if err != nil {
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, "--no-execute", "-c", command)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			return err
+		}
+		return errors.New(message)
+	}
+	return nil
+}
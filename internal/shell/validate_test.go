@@ -0,0 +1,29 @@
+package shell
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		shellName string
+		wantErr   bool
+	}{
+		{"valid bash", "for f in *.go; do echo $f; done", "bash", false},
+		{"unclosed quote", `echo "hello`, "bash", true},
+		{"missing done", "for f in *; do echo $f", "bash", true},
+		{"bash array in sh", "a=(1 2)", "sh", true},
+		{"bash array in bash", "a=(1 2)", "bash", false},
+		{"zsh", "print -l ${(s: :)PATH}", "zsh", false},
+		{"zsh glob qualifier", "ls **/*.go(.)", "zsh", false},
+		{"unchecked shell", "Get-ChildItem | Where-Object {", "powershell", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Validate(test.command, test.shellName); (err != nil) != test.wantErr {
+				t.Errorf("Validate(%q) error = %v, want error %v", test.command, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -9,10 +9,14 @@ import (
 )
 
 type SystemInfo struct {
-	OS        string // "darwin", "linux", "windows", "freebsd", etc.
-	OSName    string // "macOS", "Ubuntu", "Windows", "FreeBSD", etc.
-	Shell     string // "bash", "zsh", "fish", "powershell", "cmd", etc.
-	ShellPath string // Full path to shell executable
+	OS        string         // "darwin", "linux", "windows", "freebsd", etc.
+	OSName    string         // "macOS", "Ubuntu", "Windows", "FreeBSD", etc.
+	Shell     string         // "bash", "zsh", "fish", "powershell", "cmd", etc.
+	ShellPath string         // Full path to shell executable
+	Project   *ProjectInfo   // Project in the working directory, nil unless detection is enabled
+	Tools     *ToolInventory // Installed CLI tools, nil if not probed
+
+	otherOS bool // Describes another machine's OS, so PATH says nothing about it
 }
 
 // Detect the current operating system and shell
@@ -102,6 +106,9 @@ func detectShell() (string, string) {
 
 // Return the likely package manager for the system
 func (s *SystemInfo) GetPackageManager() string {
+	if s.otherOS {
+		return packageManagerForName(s.OS, s.OSName)
+	}
 	switch s.OS {
 	case "darwin":
 		return "brew"
@@ -143,3 +150,73 @@ func commandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
 }
+
+// Copy the system info for another OS or shell, e.g. to answer for a
+// different machine. Empty values keep what was detected
+func (s *SystemInfo) ForTarget(osName, shell string) *SystemInfo {
+	if osName == "" && shell == "" {
+		return s
+	}
+	info := *s
+	if osName != "" {
+		info.OS = goosForName(osName)
+		info.OSName = osName
+		// Installed tools and package managers describe this machine, not the target
+		info.Tools = nil
+		info.otherOS = true
+	}
+	if shell != "" {
+		info.Shell = strings.ToLower(shell)
+		info.ShellPath = info.Shell
+	}
+	return &info
+}
+
+// Guess the package manager of another machine from its OS and distribution name
+func packageManagerForName(goos, osName string) string {
+	switch goos {
+	case "darwin":
+		return "brew"
+	case "windows":
+		return "winget"
+	case "freebsd":
+		return "pkg"
+	case "openbsd":
+		return "pkg_add"
+	case "netbsd":
+		return "pkgin"
+	}
+
+	name := strings.ToLower(osName)
+	for _, distro := range []struct {
+		names          []string
+		packageManager string
+	}{
+		{[]string{"ubuntu", "debian", "mint", "pop", "elementary", "kali", "raspbian"}, "apt"},
+		{[]string{"fedora", "red hat", "rhel", "centos", "rocky", "alma", "amazon"}, "dnf"},
+		{[]string{"arch", "manjaro", "endeavour"}, "pacman"},
+		{[]string{"suse"}, "zypper"},
+		{[]string{"alpine"}, "apk"},
+	} {
+		for _, distroName := range distro.names {
+			if strings.Contains(name, distroName) {
+				return distro.packageManager
+			}
+		}
+	}
+	return "unknown"
+}
+
+// Map an OS name to its GOOS value, assuming Linux for distribution names
+func goosForName(osName string) string {
+	switch strings.ToLower(osName) {
+	case "macos", "mac", "osx", "darwin":
+		return "darwin"
+	case "windows":
+		return "windows"
+	case "freebsd", "openbsd", "netbsd":
+		return strings.ToLower(osName)
+	default:
+		return "linux"
+	}
+}
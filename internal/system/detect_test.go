@@ -0,0 +1,68 @@
+package system
+
+import "testing"
+
+func TestForTarget(t *testing.T) {
+	host := &SystemInfo{OS: "linux", OSName: "Arch Linux", Shell: "zsh", ShellPath: "/usr/bin/zsh", Tools: &ToolInventory{Available: []string{"jq"}}}
+
+	tests := []struct {
+		name               string
+		osName             string
+		shell              string
+		wantOS             string
+		wantShell          string
+		wantShellPath      string
+		wantTools          bool
+		wantPackageManager string
+	}{
+		{"macOS", "macOS", "", "darwin", "zsh", "/usr/bin/zsh", false, "brew"},
+		{"windows with powershell", "Windows", "PowerShell", "windows", "powershell", "powershell", false, "winget"},
+		{"ubuntu", "Ubuntu", "bash", "linux", "bash", "bash", false, "apt"},
+		{"fedora", "Fedora Linux", "", "linux", "zsh", "/usr/bin/zsh", false, "dnf"},
+		{"unknown distribution", "Gentoo", "", "linux", "zsh", "/usr/bin/zsh", false, "unknown"},
+		{"freebsd", "FreeBSD", "sh", "freebsd", "sh", "sh", false, "pkg"},
+		{"shell only", "", "fish", "linux", "fish", "fish", true, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := host.ForTarget(test.osName, test.shell)
+			if info.OS != test.wantOS || info.Shell != test.wantShell || info.ShellPath != test.wantShellPath {
+				t.Errorf("ForTarget() = %s, %s, %s, want %s, %s, %s",
+					info.OS, info.Shell, info.ShellPath, test.wantOS, test.wantShell, test.wantShellPath)
+			}
+			if (info.Tools != nil) != test.wantTools {
+				t.Errorf("Tools = %v, want kept %v", info.Tools, test.wantTools)
+			}
+			// The host's package manager depends on PATH, so is only checked for other OSes
+			if test.wantPackageManager != "" {
+				if got := info.GetPackageManager(); got != test.wantPackageManager {
+					t.Errorf("GetPackageManager() = %q, want %q", got, test.wantPackageManager)
+				}
+			}
+		})
+	}
+
+	if host.ForTarget("", "") != host {
+		t.Error("ForTarget() without a target copied the info")
+	}
+	if host.OS != "linux" || host.Shell != "zsh" || host.Tools == nil {
+		t.Error("ForTarget() changed the detected info")
+	}
+}
+
+func TestGoosForName(t *testing.T) {
+	tests := map[string]string{
+		"macOS":   "darwin",
+		"OSX":     "darwin",
+		"Windows": "windows",
+		"OpenBSD": "openbsd",
+		"Ubuntu":  "linux",
+		"":        "linux",
+	}
+	for name, want := range tests {
+		if got := goosForName(name); got != want {
+			t.Errorf("goosForName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package system
+
+// Package names that differ from the executable name, by package manager.
+// The "*" entry applies to managers without a specific entry
+var packageNames = map[string]map[string]string{
+	"rg":      {"*": "ripgrep", "choco": "ripgrep", "winget": "BurntSushi.ripgrep.MSVC"},
+	"fd":      {"*": "fd", "apt": "fd-find", "dnf": "fd-find", "yum": "fd-find", "winget": "sharkdp.fd"},
+	"gsed":    {"brew": "gnu-sed"},
+	"gawk":    {"*": "gawk"},
+	"gh":      {"*": "gh", "pacman": "github-cli", "winget": "GitHub.cli"},
+	"kubectl": {"*": "kubectl", "brew": "kubernetes-cli", "winget": "Kubernetes.kubectl"},
+	"python3": {"*": "python3", "brew": "python", "pacman": "python", "winget": "Python.Python.3"},
+	"node":    {"*": "nodejs", "brew": "node", "winget": "OpenJS.NodeJS"},
+	"docker":  {"*": "docker", "apt": "docker.io", "brew": "--cask docker"},
+	"eza":     {"*": "eza"},
+}
+
+// Return a command that installs the package providing tool, or "" if the
+// package manager is unknown
+func InstallCommand(packageManager, tool string) string {
+	pkg := tool
+	if names, ok := packageNames[tool]; ok {
+		if name, ok := names[packageManager]; ok {
+			pkg = name
+		} else if name, ok := names["*"]; ok {
+			pkg = name
+		}
+	}
+
+	switch packageManager {
+	case "brew":
+		return "brew install " + pkg
+	case "apt":
+		return "sudo apt install " + pkg
+	case "pacman":
+		return "sudo pacman -S " + pkg
+	case "dnf", "yum", "zypper":
+		return "sudo " + packageManager + " install " + pkg
+	case "pkg":
+		return "sudo pkg install " + pkg
+	case "choco", "scoop", "winget":
+		return packageManager + " install " + pkg
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,351 @@
+package system
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Maximum number of task names listed per tool
+const maxProjectTasks = 15
+
+// Project detected in the working directory
+type ProjectInfo struct {
+	Dir       string
+	Languages []string      // "Go", "JavaScript", "Rust", etc.
+	Tools     []ProjectTool // Build tools and their tasks
+	GitBranch string        // Current branch, if in a git repository
+	IsGitRepo bool
+}
+
+// A build tool and the task names it defines
+type ProjectTool struct {
+	Name     string // "go", "npm", "make", "docker compose", etc.
+	TaskKind string // "scripts", "targets", "recipes", "services"
+	Tasks    []string
+}
+
+var (
+	// Makefile rule, excluding variable assignments and special targets
+	makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=]|$)`)
+	// justfile recipe, optionally quiet (@) with parameters
+	justRecipePattern = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)(\s[^:]*)?:([^=]|$)`)
+	// TOML key at the start of a line
+	tomlKeyPattern = regexp.MustCompile(`^"?([A-Za-z0-9_.-]+)"?\s*=`)
+	// Indented key in a compose file's services block
+	composeServicePattern = regexp.MustCompile(`^( +)["']?([A-Za-z0-9_.-]+)["']?:\s*(#.*)?$`)
+)
+
+// Detect the languages, build tools and tasks of the project in dir
+func DetectProject(dir string) *ProjectInfo {
+	info := &ProjectInfo{Dir: dir}
+
+	// Go
+	if module := readGoModule(filepath.Join(dir, "go.mod")); module != "" {
+		info.addLanguage("Go")
+		info.Tools = append(info.Tools, ProjectTool{Name: "go", TaskKind: "module", Tasks: []string{module}})
+	}
+
+	// Node.js
+	if scripts, ok := readPackageScripts(filepath.Join(dir, "package.json")); ok {
+		if fileExists(filepath.Join(dir, "tsconfig.json")) {
+			info.addLanguage("TypeScript")
+		} else {
+			info.addLanguage("JavaScript")
+		}
+		info.Tools = append(info.Tools, ProjectTool{Name: nodePackageManager(dir), TaskKind: "scripts", Tasks: scripts})
+	}
+
+	// Rust
+	if fileExists(filepath.Join(dir, "Cargo.toml")) {
+		info.addLanguage("Rust")
+		info.Tools = append(info.Tools, ProjectTool{Name: "cargo"})
+	}
+
+	// Python
+	if fileExists(filepath.Join(dir, "pyproject.toml")) {
+		info.addLanguage("Python")
+		tool, scripts := readPyproject(dir)
+		info.Tools = append(info.Tools, ProjectTool{Name: tool, TaskKind: "scripts", Tasks: scripts})
+	}
+
+	// Make
+	for _, name := range []string{"GNUmakefile", "Makefile", "makefile"} {
+		if targets, ok := readTasks(filepath.Join(dir, name), makeTargetPattern); ok {
+			info.Tools = append(info.Tools, ProjectTool{Name: "make", TaskKind: "targets", Tasks: targets})
+			break
+		}
+	}
+
+	// just
+	for _, name := range []string{"justfile", "Justfile", ".justfile"} {
+		if recipes, ok := readTasks(filepath.Join(dir, name), justRecipePattern); ok {
+			info.Tools = append(info.Tools, ProjectTool{Name: "just", TaskKind: "recipes", Tasks: recipes})
+			break
+		}
+	}
+
+	// Docker Compose
+	for _, name := range []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"} {
+		if services, ok := readComposeServices(filepath.Join(dir, name)); ok {
+			info.Tools = append(info.Tools, ProjectTool{Name: "docker compose", TaskKind: "services", Tasks: services})
+			break
+		}
+	}
+
+	// Git, which may be rooted in a parent directory
+	if gitDir := findGitDir(dir); gitDir != "" {
+		info.IsGitRepo = true
+		info.GitBranch = readGitBranch(gitDir)
+	}
+
+	return info
+}
+
+// Check if anything was detected
+func (p *ProjectInfo) IsEmpty() bool {
+	return len(p.Languages) == 0 && len(p.Tools) == 0 && !p.IsGitRepo
+}
+
+// Returns a concise multi-line summary for AI context
+func (p *ProjectInfo) Summary() string {
+	var lines []string
+	if len(p.Languages) > 0 {
+		lines = append(lines, "Languages: "+strings.Join(p.Languages, ", "))
+	}
+	for _, tool := range p.Tools {
+		line := "Build tool: " + tool.Name
+		if len(tool.Tasks) > 0 {
+			line += fmt.Sprintf(" (%s: %s)", tool.TaskKind, strings.Join(tool.Tasks, ", "))
+		}
+		lines = append(lines, line)
+	}
+	if p.IsGitRepo {
+		if p.GitBranch != "" {
+			lines = append(lines, "Git repository on branch "+p.GitBranch)
+		} else {
+			lines = append(lines, "Git repository")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *ProjectInfo) addLanguage(language string) {
+	if !slices.Contains(p.Languages, language) {
+		p.Languages = append(p.Languages, language)
+	}
+}
+
+// Read the module path from go.mod
+func readGoModule(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	return ""
+}
+
+// Read script names from package.json
+func readPackageScripts(path string) ([]string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, true
+	}
+
+	scripts := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		scripts = append(scripts, name)
+	}
+	slices.Sort(scripts)
+	return limitTasks(scripts), true
+}
+
+// Pick the Node.js package manager from lockfiles
+func nodePackageManager(dir string) string {
+	switch {
+	case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		return "pnpm"
+	case fileExists(filepath.Join(dir, "yarn.lock")):
+		return "yarn"
+	case fileExists(filepath.Join(dir, "bun.lockb")), fileExists(filepath.Join(dir, "bun.lock")):
+		return "bun"
+	default:
+		return "npm"
+	}
+}
+
+// Pick the Python project tool and read script names from pyproject.toml
+func readPyproject(dir string) (string, []string) {
+	file, err := os.Open(filepath.Join(dir, "pyproject.toml"))
+	if err != nil {
+		return "pip", nil
+	}
+	defer file.Close()
+
+	tool := "pip"
+	if fileExists(filepath.Join(dir, "uv.lock")) {
+		tool = "uv"
+	}
+
+	var scripts []string
+	var section string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			switch {
+			case strings.HasPrefix(section, "tool.poetry"):
+				tool = "poetry"
+			case strings.HasPrefix(section, "tool.pdm") && tool == "pip":
+				tool = "pdm"
+			case strings.HasPrefix(section, "tool.hatch") && tool == "pip":
+				tool = "hatch"
+			}
+			continue
+		}
+		if section == "project.scripts" || section == "tool.poetry.scripts" {
+			if match := tomlKeyPattern.FindStringSubmatch(line); match != nil {
+				scripts = append(scripts, match[1])
+			}
+		}
+	}
+	return tool, limitTasks(scripts)
+}
+
+// Read task names from lines matching pattern, e.g. Makefile targets
+func readTasks(path string, pattern *regexp.Regexp) ([]string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var tasks []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := pattern.FindStringSubmatch(scanner.Text())
+		if match == nil || slices.Contains(tasks, match[1]) {
+			continue
+		}
+		tasks = append(tasks, match[1])
+	}
+	return limitTasks(tasks), true
+}
+
+// Read service names from a compose file's services block
+func readComposeServices(path string) ([]string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var services []string
+	var inServices bool
+	var indent string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Top-level keys start a new block
+		if line != "" && line[0] != ' ' && line[0] != '#' {
+			inServices = strings.HasPrefix(line, "services:")
+			indent = ""
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		// Services are the keys at the indentation of the first one
+		match := composeServicePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if indent == "" {
+			indent = match[1]
+		}
+		if match[1] == indent {
+			services = append(services, match[2])
+		}
+	}
+	return limitTasks(services), true
+}
+
+// Find the .git directory in dir or its parents
+func findGitDir(dir string) string {
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if stat, err := os.Stat(gitDir); err == nil {
+			if stat.IsDir() {
+				return gitDir
+			}
+			return readGitFile(gitDir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Follow the "gitdir:" pointer in a .git file, as used by worktrees and
+// submodules. Relative paths are relative to the file's directory
+func readGitFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir
+}
+
+// Read the current branch from .git/HEAD
+func readGitBranch(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return branch
+	}
+	return ""
+}
+
+// Cap the number of task names to keep the prompt short
+func limitTasks(tasks []string) []string {
+	if len(tasks) > maxProjectTasks {
+		return append(tasks[:maxProjectTasks:maxProjectTasks], "...")
+	}
+	return tasks
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,147 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadComposeServices(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "two-space indent",
+			content: "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\nvolumes:\n  data:\n",
+			want:    []string{"web", "db"},
+		},
+		{
+			name:    "four-space indent",
+			content: "version: '3'\nservices:\n    web:\n        ports:\n            - 80:80\n    worker:\n        image: app\n",
+			want:    []string{"web", "worker"},
+		},
+		{
+			name:    "comments and quotes",
+			content: "services:\n  # the app\n  \"api\":  # main service\n    build: .\n\n  cache:\n    image: redis\n",
+			want:    []string{"api", "cache"},
+		},
+		{
+			name:    "no services",
+			content: "volumes:\n  data:\n",
+			want:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "compose.yaml")
+			writeFile(t, path, test.content)
+
+			got, ok := readComposeServices(path)
+			if !ok {
+				t.Fatal("readComposeServices() not ok")
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("readComposeServices() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadTasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		pattern string
+		want    []string
+	}{
+		{"make", "CC := gcc\nall: build\nbuild:\n\tgo build\n.PHONY: all\nbuild: extra\n", "make", []string{"all", "build"}},
+		{"just", "set shell := [\"bash\"]\ntest:\n  go test\n@fmt target:\n  gofmt\n", "just", []string{"test", "fmt"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+			writeFile(t, path, test.content)
+
+			pattern := makeTargetPattern
+			if test.pattern == "just" {
+				pattern = justRecipePattern
+			}
+			got, _ := readTasks(path, pattern)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("readTasks() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindGitDir(t *testing.T) {
+	root := t.TempDir()
+
+	// Regular repository, found from a subdirectory
+	repo := filepath.Join(root, "repo")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	sub := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Worktree whose .git file points into the main repository
+	worktreeGitDir := filepath.Join(repo, ".git", "worktrees", "feature")
+	writeFile(t, filepath.Join(worktreeGitDir, "HEAD"), "ref: refs/heads/feature/x\n")
+	worktree := filepath.Join(root, "feature")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+worktreeGitDir+"\n")
+
+	// Submodule with a relative pointer and a detached HEAD
+	writeFile(t, filepath.Join(repo, ".git", "modules", "lib", "HEAD"), "0123456789abcdef\n")
+	submodule := filepath.Join(repo, "lib")
+	writeFile(t, filepath.Join(submodule, ".git"), "gitdir: ../.git/modules/lib\n")
+
+	tests := []struct {
+		name       string
+		dir        string
+		wantBranch string
+	}{
+		{"repository root", repo, "main"},
+		{"subdirectory", sub, "main"},
+		{"worktree", worktree, "feature/x"},
+		{"detached submodule", submodule, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gitDir := findGitDir(test.dir)
+			if gitDir == "" {
+				t.Fatal("findGitDir() found nothing")
+			}
+			if got := readGitBranch(gitDir); got != test.wantBranch {
+				t.Errorf("readGitBranch(%q) = %q, want %q", gitDir, got, test.wantBranch)
+			}
+		})
+	}
+}
+
+func TestLimitTasks(t *testing.T) {
+	tasks := make([]string, maxProjectTasks+5)
+	got := limitTasks(tasks)
+	if len(got) != maxProjectTasks+1 || got[maxProjectTasks] != "..." {
+		t.Errorf("limitTasks() returned %d tasks ending in %q", len(got), got[len(got)-1])
+	}
+	if len(limitTasks([]string{"a"})) != 1 {
+		t.Error("limitTasks() changed a short list")
+	}
+}
@@ -0,0 +1,112 @@
+package system
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// How long probed tool availability is trusted before checking PATH again
+const toolCacheTTL = 24 * time.Hour
+
+// CLI tools probed by default
+var DefaultTools = []string{
+	"jq", "yq", "rg", "fd", "fzf", "gh", "git", "docker", "podman", "kubectl",
+	"helm", "gawk", "gsed", "curl", "wget", "rsync", "tmux", "bat", "eza", "ffmpeg",
+	"python3", "node",
+}
+
+// CLI tools found, or not found, on PATH
+type ToolInventory struct {
+	Available   []string
+	Unavailable []string
+}
+
+// Cached tool availability, stored as JSON
+type toolCache struct {
+	Path      string          `json:"path"` // PATH the tools were probed with
+	CheckedAt time.Time       `json:"checked_at"`
+	Tools     map[string]bool `json:"tools"`
+}
+
+// Probe PATH for tools concurrently, reusing results cached at cachePath
+// while PATH is unchanged. An empty cachePath disables caching
+func DetectTools(tools []string, cachePath string) *ToolInventory {
+	path := os.Getenv("PATH")
+	cache := loadToolCache(cachePath)
+	if cache == nil || cache.Path != path || time.Since(cache.CheckedAt) > toolCacheTTL {
+		cache = &toolCache{Path: path, CheckedAt: time.Now(), Tools: map[string]bool{}}
+	}
+
+	// Probe tools missing from the cache
+	var missing []string
+	for _, tool := range tools {
+		if _, ok := cache.Tools[tool]; !ok {
+			missing = append(missing, tool)
+		}
+	}
+	if len(missing) > 0 {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, tool := range missing {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				exists := commandExists(tool)
+				mu.Lock()
+				cache.Tools[tool] = exists
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		saveToolCache(cachePath, cache)
+	}
+
+	inventory := &ToolInventory{}
+	for _, tool := range tools {
+		if cache.Tools[tool] {
+			inventory.Available = append(inventory.Available, tool)
+		} else {
+			inventory.Unavailable = append(inventory.Unavailable, tool)
+		}
+	}
+	return inventory
+}
+
+// Check if a tool was found
+func (t *ToolInventory) Has(tool string) bool {
+	return slices.Contains(t.Available, tool)
+}
+
+func loadToolCache(cachePath string) *toolCache {
+	if cachePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil
+	}
+	var cache toolCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Tools == nil {
+		return nil
+	}
+	return &cache
+}
+
+// Write the cache, ignoring errors since it is only an optimization
+func saveToolCache(cachePath string, cache *toolCache) {
+	if cachePath == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, data, 0600)
+}
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestDetectTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses executable scripts on PATH")
+	}
+
+	bin := t.TempDir()
+	writeFile(t, filepath.Join(bin, "jq"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(bin, "jq"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	cachePath := filepath.Join(t.TempDir(), "tools.json")
+
+	inventory := DetectTools([]string{"jq", "rg"}, cachePath)
+	if !slices.Equal(inventory.Available, []string{"jq"}) || !slices.Equal(inventory.Unavailable, []string{"rg"}) {
+		t.Fatalf("DetectTools() = %+v", inventory)
+	}
+	if !inventory.Has("jq") || inventory.Has("rg") {
+		t.Errorf("Has() disagrees with %+v", inventory)
+	}
+
+	// Cached results are reused while PATH is unchanged
+	if err := os.Remove(filepath.Join(bin, "jq")); err != nil {
+		t.Fatal(err)
+	}
+	if inventory := DetectTools([]string{"jq"}, cachePath); !inventory.Has("jq") {
+		t.Error("cached result not reused")
+	}
+
+	// A different PATH probes again
+	t.Setenv("PATH", t.TempDir())
+	if inventory := DetectTools([]string{"jq"}, cachePath); inventory.Has("jq") {
+		t.Error("cache reused after PATH changed")
+	}
+}
+
+func TestDetectToolsWithoutCache(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	inventory := DetectTools([]string{"jq"}, "")
+	if len(inventory.Available) != 0 || !slices.Equal(inventory.Unavailable, []string{"jq"}) {
+		t.Errorf("DetectTools() = %+v", inventory)
+	}
+}
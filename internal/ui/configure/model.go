@@ -79,14 +79,14 @@ func (m *Model) setupModelList() {
 	models := config.ProviderModels[m.selectedProvider]
 	modelItems := make([]list.Item, len(models)+1)
 	for i, model := range models {
-		modelItems[i] = item{title: model, desc: ""}
+		// Show capabilities beneath each model
+		modelItems[i] = item{title: model, desc: m.config.ResolveCapabilities(model).Summary()}
 	}
 	// Add custom model option
 	modelItems[len(models)] = item{title: "Model not listed?", desc: ""}
 
 	delegate := list.NewDefaultDelegate()
 	delegate.SetSpacing(0)
-	delegate.ShowDescription = false
 
 	m.modelList = list.New(modelItems, delegate, m.width, m.height-4)
 	m.modelList.Title = fmt.Sprintf("%s - Select Model", m.selectedProvider)
@@ -0,0 +1,255 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/clipboard"
+	"github.com/connorgannaway/how/internal/history"
+	"github.com/connorgannaway/how/internal/ui/styles"
+)
+
+type state int
+
+const (
+	stateList state = iota
+	stateDetail
+	stateDone
+)
+
+// Bubbletea model for browsing history
+type Model struct {
+	state    state
+	list     list.Model
+	selected *history.Entry
+	reask    string // Question to ask again after quitting
+	copied   bool
+	width    int
+	height   int
+}
+
+// Item for list
+type item struct {
+	entry history.Entry
+}
+
+func (i item) Title() string { return ai.FormatQuestion(i.entry.Question) }
+
+func (i item) Description() string {
+	var command string
+	if len(i.entry.Response.Commands) > 0 {
+		command, _, _ = strings.Cut(i.entry.Response.Commands[0], "\n")
+	}
+	return fmt.Sprintf("%s · %s", formatAge(i.entry.Timestamp), command)
+}
+
+// Search questions and commands
+func (i item) FilterValue() string {
+	return i.entry.Question + " " + strings.Join(i.entry.Response.Commands, " ")
+}
+
+type clipboardMsg struct {
+	success bool
+}
+
+// Create model for history UI, listing the newest entries first
+func NewModel(entries []history.Entry) Model {
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[len(entries)-1-i] = item{entry: entry}
+	}
+
+	delegate := list.NewDefaultDelegate()
+	delegate.SetSpacing(0)
+
+	historyList := list.New(items, delegate, 0, 0)
+	historyList.Title = "History"
+	historyList.SetShowHelp(false)
+	historyList.SetStatusBarItemName("answer", "answers")
+
+	return Model{
+		state: stateList,
+		list:  historyList,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.list.SetSize(msg.Width, msg.Height-2)
+		return m, nil
+
+	case clipboardMsg:
+		m.copied = msg.success
+		m.state = stateDone
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.state = stateDone
+			return m, tea.Quit
+		}
+
+		switch m.state {
+		case stateList:
+			// Let the filter input handle keys while typing
+			if m.list.FilterState() == list.Filtering {
+				break
+			}
+			selected, ok := m.list.SelectedItem().(item)
+			switch msg.String() {
+			case "q", "esc":
+				if m.list.FilterState() == list.FilterApplied {
+					m.list.ResetFilter()
+					return m, nil
+				}
+				m.state = stateDone
+				return m, tea.Quit
+			case "enter":
+				if ok {
+					m.selected = &selected.entry
+					m.state = stateDetail
+				}
+				return m, nil
+			case "c":
+				if ok {
+					return m, copyToClipboard(selected.entry.Response.Commands)
+				}
+				return m, nil
+			case "a":
+				if ok {
+					m.reask = selected.entry.Question
+					m.state = stateDone
+					return m, tea.Quit
+				}
+				return m, nil
+			}
+
+		case stateDetail:
+			switch msg.String() {
+			case "q":
+				m.state = stateDone
+				return m, tea.Quit
+			case "esc", "backspace":
+				m.state = stateList
+			case "c":
+				return m, copyToClipboard(m.selected.Response.Commands)
+			case "a":
+				m.reask = m.selected.Question
+				m.state = stateDone
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case stateList:
+		help := styles.HelpStyle.Render("/: search • enter: show • c: copy • a: ask again • q: quit")
+		return m.list.View() + "\n" + help
+
+	case stateDetail:
+		return lipgloss.NewStyle().Padding(1, 2).Render(m.detailView())
+	}
+	return ""
+}
+
+// Render a past answer the way it was first shown
+func (m Model) detailView() string {
+	width := styles.DefaultMaxWidth
+	if m.width > 0 && m.width-4 < width {
+		width = m.width - 4
+	}
+
+	entry := m.selected
+	response := entry.Response
+	var parts []string
+	parts = append(parts, styles.QuestionStyle.Width(width).Render("⚡ "+ai.FormatQuestion(entry.Question)))
+
+	meta := fmt.Sprintf("%s · %s/%s", entry.Timestamp.Local().Format("2006-01-02 15:04"), entry.Provider, entry.Model)
+	if entry.SysInfo != nil {
+		meta += fmt.Sprintf(" · %s, %s", entry.SysInfo.OSName, entry.SysInfo.Shell)
+	}
+	parts = append(parts, styles.MutedStyle.Width(width).Render(meta), "")
+
+	if response.Title != "" {
+		parts = append(parts, styles.TitleStyle.Width(width).Render(response.Title))
+	}
+	if response.Description != "" {
+		parts = append(parts, styles.DescriptionStyle.Width(width).Render(response.Description))
+	}
+	for _, cmd := range response.Commands {
+		// Don't render prompt symbol for potential scripts
+		if strings.Contains(cmd, "\n") {
+			parts = append(parts, styles.CommandStyle.Width(width).Render(cmd))
+		} else {
+			parts = append(parts, styles.CommandStyle.Width(width).Render(styles.PromptSymbol+cmd))
+		}
+	}
+	parts = append(parts, styles.HelpStyle.MarginLeft(0).Render("c: copy • a: ask again • esc: back • q: quit"))
+	return strings.Join(parts, "\n")
+}
+
+// clipboard wrapper for usage with tea commands
+func copyToClipboard(commands []string) tea.Cmd {
+	return func() tea.Msg {
+		err := clipboard.CopyCommands(commands)
+		return clipboardMsg{success: err == nil}
+	}
+}
+
+// Format how long ago a time was, e.g. "5m ago" or "Jan 2"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	default:
+		return t.Local().Format("Jan 2, 2006")
+	}
+}
+
+// Start UI. Returns the question to ask again, or "" if none was chosen
+func Run(entries []history.Entry) (string, error) {
+	if len(entries) == 0 {
+		fmt.Println("No history yet.")
+		return "", nil
+	}
+
+	p := tea.NewProgram(NewModel(entries), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if finalModel, ok := finalModel.(Model); ok {
+		if finalModel.copied {
+			fmt.Println(styles.SuccessStyle.Render("✓ Copied to clipboard"))
+		}
+		return finalModel.reask, nil
+	}
+	return "", nil
+}
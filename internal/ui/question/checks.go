@@ -0,0 +1,74 @@
+package question
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/shell"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Executable used by a command that was not found
+type missingExecutable struct {
+	name        string
+	installHint string // Install command, empty if unknown
+}
+
+// Find executables the commands invoke that are not on PATH or shell builtins
+func findMissingExecutables(commands []string, sysInfo *system.SystemInfo) []missingExecutable {
+	var names []string
+	for _, command := range commands {
+		for _, name := range shell.MissingExecutables(command, sysInfo.Shell) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	packageManager := sysInfo.GetPackageManager()
+	missing := make([]missingExecutable, len(names))
+	for i, name := range names {
+		missing[i] = missingExecutable{
+			name:        name,
+			installHint: system.InstallCommand(packageManager, name),
+		}
+	}
+	return missing
+}
+
+// Check each command for syntax errors in the user's shell.
+// Returns one entry per command, nil when it parsed
+func findSyntaxErrors(commands []string, shellName string) []error {
+	// Placeholders such as <PORT> would parse as redirections
+	values := make(map[string]string)
+	for _, placeholder := range ai.FindPlaceholders(commands) {
+		values[placeholder.Token] = "placeholder"
+	}
+	commands = ai.FillPlaceholders(commands, values)
+
+	var syntaxErrors []error
+	for i, command := range commands {
+		if err := shell.Validate(command, shellName); err != nil {
+			if syntaxErrors == nil {
+				syntaxErrors = make([]error, len(commands))
+			}
+			syntaxErrors[i] = err
+		}
+	}
+	return syntaxErrors
+}
+
+// Describe syntax errors for a correction request
+func syntaxProblems(syntaxErrors []error) []string {
+	var problems []string
+	for i, err := range syntaxErrors {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("command %d: %v", i+1, err))
+		}
+	}
+	return problems
+}
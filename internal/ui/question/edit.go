@@ -0,0 +1,113 @@
+package question
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/connorgannaway/how/internal/shell"
+	"github.com/connorgannaway/how/internal/ui/styles"
+)
+
+// Load the commands into an editor. Single commands use a text input,
+// scripts and multiple commands use a text area
+func (m Model) startEditing() (Model, tea.Cmd) {
+	text := strings.Join(m.response.Commands, "\n")
+	m.editing = true
+	m.editMultiline = strings.Contains(text, "\n")
+
+	if m.editMultiline {
+		m.editArea = textarea.New()
+		m.editArea.ShowLineNumbers = false
+		m.editArea.Prompt = ""
+		m.editArea.CharLimit = 0
+		m.editArea.SetWidth(styles.DefaultMaxWidth)
+		m.editArea.SetHeight(min(strings.Count(text, "\n")+2, 15))
+		m.editArea.SetValue(text)
+		return m, m.editArea.Focus()
+	}
+
+	m.editInput = textinput.New()
+	m.editInput.Prompt = styles.PromptSymbol
+	m.editInput.CharLimit = 0
+	m.editInput.SetValue(text)
+	m.editInput.CursorEnd()
+	return m, m.editInput.Focus()
+}
+
+// Handle messages while editing
+func (m Model) updateEditing(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			m.state = stateDone
+			return m, tea.Quit
+		case "esc":
+			m.editing = false
+			return m, nil
+		case "ctrl+s":
+			return m.saveEdit()
+		case "enter":
+			if !m.editMultiline {
+				return m.saveEdit()
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.editMultiline {
+		m.editArea, cmd = m.editArea.Update(msg)
+	} else {
+		m.editInput, cmd = m.editInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// Replace the commands with the edited text, check them again and copy them
+func (m Model) saveEdit() (tea.Model, tea.Cmd) {
+	m.editing = false
+
+	var commands []string
+	if m.editMultiline && strings.Contains(strings.Join(m.response.Commands, ""), "\n") {
+		// Keep scripts as a single script
+		if text := strings.TrimSpace(m.editArea.Value()); text != "" {
+			commands = []string{text}
+		}
+	} else {
+		text := m.editInput.Value()
+		if m.editMultiline {
+			text = m.editArea.Value()
+		}
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commands = append(commands, line)
+			}
+		}
+	}
+	if len(commands) == 0 {
+		return m, nil
+	}
+
+	// Edits are saved on the shared response, so they are what gets remembered
+	m.response.Commands = commands
+	m.response.Edited = true
+	m.copied = false
+	m.missing = findMissingExecutables(commands, m.request.SysInfo)
+	m.syntaxErrors = findSyntaxErrors(commands, m.request.SysInfo.Shell)
+	m.risk = shell.AnalyzeAll(commands, m.request.SysInfo.Shell)
+
+	m.confirmingCopy = m.risk.IsHighRisk()
+	if m.confirmingCopy {
+		return m, nil
+	}
+	return m, m.copyCommands()
+}
+
+// Render the editor with its key help
+func (m Model) editView() string {
+	if m.editMultiline {
+		return m.editArea.View() + "\n" + styles.HelpStyle.MarginLeft(0).Render("ctrl+s: save • esc: cancel")
+	}
+	return m.editInput.View() + "\n" + styles.HelpStyle.MarginLeft(0).Render("enter: save • esc: cancel")
+}
@@ -2,16 +2,22 @@ package question
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/connorgannaway/how/internal/ai"
 	"github.com/connorgannaway/how/internal/clipboard"
-	"github.com/connorgannaway/how/internal/system"
+	"github.com/connorgannaway/how/internal/shell"
 	"github.com/connorgannaway/how/internal/ui/styles"
 )
 
@@ -24,22 +30,65 @@ const (
 	stateDone
 )
 
+// Options for the question UI
+type Options struct {
+	ShowReasoning bool         // Keep the UI open with a collapsible reasoning summary when one is returned
+	InputTTY      bool         // Read keys from the terminal instead of stdin, for when stdin is piped
+	Run           bool         // Offer to run the commands as soon as they are shown
+	Edit          bool         // Open the commands in the editor as soon as they are shown
+	Print         bool         // Render on the terminal and print the chosen commands to stdout instead of copying
+	Save          bool         // Open the save form as soon as the commands are shown
+	Format        Format       // Print the answer without the UI unless FormatTUI
+	Force         bool         // Print high-risk commands with FormatRaw and FormatQuiet
+	Model         string       // Model name included with FormatJSON
+	Stored        *ai.Response // Answer from history, shown instead of asking until the user asks again
+	StoredAt      time.Time
+	StoredName    string   // Name of the saved snippet in Stored, shown instead of the history date
+	StoredTags    []string // Tags of the saved snippet, kept when it is saved again
+}
+
 // Bubbletea model for question UI
 type Model struct {
-	question string
-	provider ai.Provider
-	sysInfo  *system.SystemInfo
-	spinner  spinner.Model
-	state    state
-	response *ai.Response
-	err      error
-	copied   bool
-	width    int
+	request           *ai.Request
+	provider          ai.Provider
+	opts              Options
+	spinner           spinner.Model
+	state             state
+	response          *ai.Response
+	err               error
+	copied            bool
+	missing           []missingExecutable
+	syntaxErrors      []error // Per command, nil entries parsed
+	correcting        bool    // Asking the model to fix syntax errors
+	risk              shell.Assessment
+	confirmingCopy    bool // High-risk commands are copied only after a key press
+	confirmingRun     bool // Waiting for y/n before running a command
+	runIndex          int  // Command awaiting confirmation, each is run separately
+	ran               bool
+	runErr            error // Exit status of the last command run, nil on success
+	editing           bool
+	editMultiline     bool // Editing a script or several commands in the text area
+	editInput         textinput.Model
+	editArea          textarea.Model
+	filling           bool // Filling in placeholders before the commands are copied
+	placeholderInputs []textinput.Model
+	placeholderFocus  int
+	saving            bool // Naming the commands to save them as a snippet
+	saveInputs        []textinput.Model
+	saveFocus         int
+	saveErr           error
+	savedName         string
+	chosen            bool // Commands accepted in print mode
+	fromHistory       bool // Showing opts.Stored
+	reasoningExpanded bool
+	width             int
 }
 
 // Bubbletea messages
 type aiResponseMsg struct {
-	response *ai.Response
+	response     *ai.Response
+	syntaxErrors []error
+	corrected    bool // Response to a correction request
 }
 
 type aiErrorMsg struct {
@@ -50,18 +99,56 @@ type clipboardMsg struct {
 	success bool
 }
 
+type chosenMsg struct{}
+
+type runFinishedMsg struct {
+	err error
+}
+
 // wrapper for ai.Provider.Ask to usage with model and tea commands
 func (m Model) askAI() tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		response, err := m.provider.Ask(ctx, m.question, m.sysInfo)
+		response, err := m.provider.Ask(ctx, m.request)
 		if err != nil {
 			return aiErrorMsg{err: err}
 		}
-		return aiResponseMsg{response: response}
+		return aiResponseMsg{
+			response:     response,
+			syntaxErrors: findSyntaxErrors(response.Commands, m.request.SysInfo.Shell),
+		}
+	}
+}
+
+// Send a response with syntax errors back to the provider once to be fixed.
+// Falls back to the original response if the retry fails
+func (m Model) correctAI(original aiResponseMsg) tea.Cmd {
+	return func() tea.Msg {
+		response, syntaxErrors := askCorrection(context.Background(), m.provider, m.request, original.response, original.syntaxErrors)
+		return aiResponseMsg{
+			response:     response,
+			syntaxErrors: syntaxErrors,
+			corrected:    true,
+		}
 	}
 }
 
+// Ask the provider to fix the syntax errors in a response, returning the
+// corrected response and its errors, or the original if the retry fails
+func askCorrection(ctx context.Context, provider ai.Provider, request *ai.Request, original *ai.Response, syntaxErrors []error) (*ai.Response, []error) {
+	correction := *request
+	correction.Correction = &ai.Correction{
+		Response: original.RawResponse,
+		Problems: syntaxProblems(syntaxErrors),
+	}
+
+	response, err := provider.Ask(ctx, &correction)
+	if err != nil || len(response.Commands) == 0 {
+		return original, syntaxErrors
+	}
+	return response, findSyntaxErrors(response.Commands, request.SysInfo.Shell)
+}
+
 // clipboard wrapper for usage with tea commands
 func copyToClipboard(commands []string) tea.Cmd {
 	return func() tea.Msg {
@@ -70,22 +157,53 @@ func copyToClipboard(commands []string) tea.Cmd {
 	}
 }
 
-func NewModel(question string, provider ai.Provider, sysInfo *system.SystemInfo) Model {
+// Run the command awaiting confirmation in the user's shell with the terminal attached
+func (m Model) runCommand() tea.Cmd {
+	cmd, cleanup, err := shell.Command(m.response.Commands[m.runIndex], m.request.SysInfo.Shell, m.request.SysInfo.ShellPath)
+	if err != nil {
+		return func() tea.Msg { return runFinishedMsg{err: err} }
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		cleanup()
+		return runFinishedMsg{err: err}
+	})
+}
+
+// Copy the commands, or in print mode accept them and quit
+func (m Model) copyCommands() tea.Cmd {
+	if m.opts.Print {
+		return func() tea.Msg { return chosenMsg{} }
+	}
+	return copyToClipboard(m.response.Commands)
+}
+
+func NewModel(request *ai.Request, provider ai.Provider, opts Options) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = styles.SpinnerStyle
 
 	return Model{
-		question: question,
-		provider: provider,
-		sysInfo:  sysInfo,
-		spinner:  s,
-		state:    stateThinking,
+		request:     request,
+		provider:    provider,
+		opts:        opts,
+		spinner:     s,
+		state:       stateThinking,
+		fromHistory: opts.Stored != nil,
 	}
 }
 
 // Kick off spinner and send question to AI provider
 func (m Model) Init() tea.Cmd {
+	// Show the stored answer right away, checked like a new one
+	if m.opts.Stored != nil {
+		return func() tea.Msg {
+			return aiResponseMsg{
+				response:     m.opts.Stored,
+				syntaxErrors: findSyntaxErrors(m.opts.Stored.Commands, m.request.SysInfo.Shell),
+				corrected:    true,
+			}
+		}
+	}
 	return tea.Batch(
 		m.spinner.Tick,
 		m.askAI(),
@@ -100,6 +218,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.editing {
+			return m.updateEditing(msg)
+		}
+		if m.filling {
+			return m.updateFilling(msg)
+		}
+		if m.saving {
+			return m.updateSaving(msg)
+		}
+
+		// Confirm, skip or cancel running each command. Commands are often
+		// alternatives, so they are never run together
+		if m.confirmingRun {
+			switch msg.String() {
+			case "y":
+				m.confirmingRun = false
+				return m, m.runCommand()
+			case "n":
+				return m.nextRun()
+			case "esc":
+				m.confirmingRun = false
+				if m.ran {
+					m.state = stateDone
+					return m, tea.Quit
+				}
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.state = stateDone
@@ -109,16 +256,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateDone
 				return m, tea.Quit
 			}
+		case "r":
+			if m.state == stateDisplaying && m.showReasoning() {
+				m.reasoningExpanded = !m.reasoningExpanded
+			}
+		case "x":
+			if m.state == stateDisplaying && len(m.response.Commands) > 0 {
+				m.confirmingRun = true
+				m.runIndex = 0
+			}
+		case "e":
+			if m.state == stateDisplaying && len(m.response.Commands) > 0 {
+				return m.startEditing()
+			}
+		case "s":
+			if m.state == stateDisplaying && len(m.response.Commands) > 0 {
+				return m.startSaving()
+			}
+		case "a":
+			if m.state == stateDisplaying && m.fromHistory && m.provider != nil {
+				return m.askAgain()
+			}
+		case "c":
+			if m.state == stateDisplaying && m.confirmingCopy {
+				m.confirmingCopy = false
+				if m.showReasoning() {
+					return m, m.copyCommands()
+				}
+				return m, tea.Sequence(m.copyCommands(), tea.Quit)
+			}
 		}
 
 	case aiResponseMsg:
+		// Retry once when a command does not parse, keeping the spinner running
+		if msg.syntaxErrors != nil && !msg.corrected {
+			m.correcting = true
+			return m, m.correctAI(msg)
+		}
 		m.response = msg.response
+		m.syntaxErrors = msg.syntaxErrors
 		m.state = stateDisplaying
-		// Auto-copy to clipboard
-		if len(m.response.Commands) > 0 {
-			return m, tea.Sequence(copyToClipboard(m.response.Commands), tea.Quit)
+		m.missing = findMissingExecutables(m.response.Commands, m.request.SysInfo)
+		m.risk = shell.AnalyzeAll(m.response.Commands, m.request.SysInfo.Shell)
+		// Stay open to fill in placeholders before anything is copied
+		if len(m.response.Placeholders) > 0 {
+			return m.startFilling()
 		}
-		return m, tea.Quit
+		return m.present()
 
 	case aiErrorMsg:
 		m.err = msg.err
@@ -129,6 +313,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.copied = msg.success
 		return m, nil
 
+	case savedMsg:
+		if msg.err != nil {
+			m.saveErr = msg.err
+			return m, nil
+		}
+		m.savedName = msg.name
+		return m.finishSaving()
+
+	case chosenMsg:
+		m.chosen = true
+		m.state = stateDone
+		return m, tea.Quit
+
+	case runFinishedMsg:
+		m.ran = true
+		m.runErr = msg.err
+		return m.nextRun()
+
 	case spinner.TickMsg:
 		if m.state == stateThinking {
 			var cmd tea.Cmd
@@ -137,10 +339,102 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Cursor blinks and other editor messages
+	if m.editing {
+		return m.updateEditing(msg)
+	}
+	if m.filling {
+		return m.updateFilling(msg)
+	}
+	if m.saving {
+		return m.updateSaving(msg)
+	}
+
 	return m, nil
 }
 
+// Move on to confirming the next command, quitting after the last one
+// if any ran
+func (m Model) nextRun() (Model, tea.Cmd) {
+	if m.runIndex+1 < len(m.response.Commands) {
+		m.runIndex++
+		m.confirmingRun = true
+		return m, nil
+	}
+	m.confirmingRun = false
+	if m.ran {
+		m.state = stateDone
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// Discard the stored answer and ask the provider
+func (m Model) askAgain() (Model, tea.Cmd) {
+	m.fromHistory = false
+	m.state = stateThinking
+	m.response = nil
+	m.missing = nil
+	m.syntaxErrors = nil
+	m.risk = shell.Assessment{}
+	m.copied = false
+	m.confirmingCopy = false
+	m.confirmingRun = false
+	m.runIndex = 0
+	// Asking again must not return the same cached answer
+	m.request.Refresh = true
+	return m, tea.Batch(m.spinner.Tick, m.askAI())
+}
+
+// Show the checked commands, copying them or waiting for input
+func (m Model) present() (Model, tea.Cmd) {
+	// Stay open to name the commands, carrying on once saved
+	if m.opts.Save && len(m.response.Commands) > 0 {
+		return m.startSaving()
+	}
+	// Stay open to edit the commands, copying them once saved
+	if m.opts.Edit && len(m.response.Commands) > 0 {
+		return m.startEditing()
+	}
+	// Stay open to confirm running the commands
+	if m.opts.Run && len(m.response.Commands) > 0 {
+		m.confirmingRun = true
+		m.runIndex = 0
+		m.confirmingCopy = m.risk.IsHighRisk()
+		if m.confirmingCopy {
+			return m, nil
+		}
+		return m, m.copyCommands()
+	}
+	// Stay open until the user confirms copying a high-risk command
+	if m.risk.IsHighRisk() {
+		m.confirmingCopy = true
+		return m, nil
+	}
+	// Stay open so the reasoning summary can be expanded, or to ask again
+	if m.showReasoning() || m.fromHistory {
+		if len(m.response.Commands) > 0 {
+			return m, m.copyCommands()
+		}
+		return m, nil
+	}
+	// Auto-copy to clipboard
+	if len(m.response.Commands) > 0 {
+		return m, tea.Sequence(m.copyCommands(), tea.Quit)
+	}
+	return m, tea.Quit
+}
+
 func (m Model) View() string {
+    // The answer was shown before the commands ran, only report how the last one exited
+    if m.ran && !m.confirmingRun {
+        return lipgloss.NewStyle().Padding(1, 2).Render(m.runStatus())
+    }
+    // Clear the terminal in print mode so the shell can redraw its prompt
+    if m.opts.Print && m.state == stateDone {
+        return ""
+    }
+
     var parts []string
 
     // Calculate effective width for text wrapping (min of terminal width - padding, or max 80)
@@ -153,37 +447,123 @@ func (m Model) View() string {
     }
 
     // Show question
-    parts = append(parts, styles.QuestionStyle.Width(effectiveWidth).Render("⚡ " + ai.BuildUserPrompt(m.question)))
+    parts = append(parts, styles.QuestionStyle.Width(effectiveWidth).Render("⚡ " + ai.FormatQuestion(m.request.Question)))
+    if len(m.request.Attachments) > 0 {
+        var names []string
+        for _, attachment := range m.request.Attachments {
+            names = append(names, attachment.Name)
+        }
+        parts = append(parts, styles.MutedStyle.Width(effectiveWidth).Render("📎 " + strings.Join(names, ", ")))
+    }
     parts = append(parts, "")
 
     switch m.state {
 
 	// Show spinner while waiting for response
     case stateThinking:
-        parts = append(parts, m.spinner.View()+" "+styles.MutedStyle.Width(effectiveWidth).Render("Thinking..."))
+        status := "Thinking..."
+        if m.correcting {
+            status = "Fixing shell syntax..."
+        }
+        parts = append(parts, m.spinner.View()+" "+styles.MutedStyle.Width(effectiveWidth).Render(status))
 
 	// Display response parts
     case stateDisplaying:
         if m.response != nil {
+            if m.fromHistory && m.opts.StoredName != "" {
+                parts = append(parts, styles.MutedStyle.Render("★ Saved as @" + m.opts.StoredName))
+            } else if m.fromHistory {
+                parts = append(parts, styles.MutedStyle.Render("↺ From history, " + m.opts.StoredAt.Local().Format("Jan 2 15:04")))
+            }
             if m.response.Title != "" {
                 parts = append(parts, styles.TitleStyle.Width(effectiveWidth).Render(m.response.Title))
             }
             if m.response.Description != "" {
                 parts = append(parts, styles.DescriptionStyle.Width(effectiveWidth).Render(m.response.Description))
             }
-            if len(m.response.Commands) > 0 {
-                for _, cmd := range m.response.Commands {
+            if m.risk.Risk != shell.RiskSafe {
+                banner := fmt.Sprintf("⚠ %s: %s", strings.ToUpper(m.risk.Risk.String()), m.risk.Reason)
+                if m.risk.IsHighRisk() {
+                    parts = append(parts, styles.DangerBannerStyle.Render(banner))
+                } else {
+                    parts = append(parts, styles.NoticeStyle.Width(effectiveWidth).Render(banner))
+                }
+            }
+            if m.filling {
+                parts = append(parts, "", m.fillView(), "")
+            }
+            if m.editing {
+                parts = append(parts, "", m.editView(), "")
+            } else if len(m.response.Commands) > 0 {
+                for i, cmd := range m.response.Commands {
 					// Don't render prompt symbol for potential scripts
                     if strings.Contains(cmd, "\n") {
                         parts = append(parts, styles.CommandStyle.Width(effectiveWidth).Render(cmd))
                     } else {
                         parts = append(parts, styles.CommandStyle.Width(effectiveWidth).Render(styles.PromptSymbol + cmd))
                     }
+                    if m.syntaxErrors != nil && m.syntaxErrors[i] != nil {
+                        notice := fmt.Sprintf("⚠ Invalid %s syntax: %v", m.request.SysInfo.Shell, m.syntaxErrors[i])
+                        parts = append(parts, styles.NoticeStyle.Width(effectiveWidth).Render(notice))
+                    }
+                }
+            }
+            for _, missing := range m.missing {
+                notice := "⚠ " + missing.name + " is not installed"
+                if missing.installHint != "" {
+                    notice += " · install with: " + missing.installHint
                 }
+                parts = append(parts, styles.NoticeStyle.Width(effectiveWidth).Render(notice))
+            }
+            if m.showReasoning() {
+                if m.reasoningExpanded {
+                    parts = append(parts, styles.MutedStyle.Render("▾ Reasoning"))
+                    parts = append(parts, styles.ReasoningStyle.Width(effectiveWidth).Render(m.response.Reasoning))
+                } else {
+                    parts = append(parts, styles.MutedStyle.Render("▸ Reasoning"))
+                }
+            }
+            if m.saving {
+                parts = append(parts, "", m.saveView())
             }
             if m.copied {
                 parts = append(parts, "", styles.SuccessStyle.Render("✓ Copied to clipboard"))
             }
+            if m.savedName != "" {
+                parts = append(parts, styles.SuccessStyle.Render("★ Saved, recall with: how @" + m.savedName))
+            }
+            if m.confirmingRun {
+                if m.ran {
+                    parts = append(parts, "", m.runStatus())
+                }
+                prompt := fmt.Sprintf("Run in %s? ", m.request.SysInfo.ShellPath)
+                keys := "y: run • n: cancel"
+                if len(m.response.Commands) > 1 {
+                    prompt = fmt.Sprintf("Run command %d of %d in %s? ", m.runIndex+1, len(m.response.Commands), m.request.SysInfo.ShellPath)
+                    keys = "y: run • n: skip • esc: cancel"
+                    parts = append(parts, "", styles.CommandStyle.Width(effectiveWidth).Render(m.response.Commands[m.runIndex]))
+                }
+                parts = append(parts, "", styles.NoticeStyle.Render(prompt) + styles.MutedStyle.Render(keys))
+            }
+            var help []string
+            if len(m.response.Commands) > 0 {
+                help = append(help, "x: run", "e: edit", "s: save")
+            }
+            if m.fromHistory && m.provider != nil {
+                help = append(help, "a: ask again")
+            }
+            if m.confirmingCopy && m.opts.Print {
+                help = append(help, "c: use anyway")
+            } else if m.confirmingCopy {
+                help = append(help, "c: copy anyway")
+            }
+            if m.showReasoning() {
+                help = append(help, "r: toggle reasoning")
+            }
+            // Only shown while the UI stays open for input
+            if !m.confirmingRun && !m.editing && !m.filling && !m.saving && (m.confirmingCopy || m.showReasoning() || m.opts.Run || m.opts.Edit || m.fromHistory) {
+                parts = append(parts, styles.HelpStyle.MarginLeft(0).Render(strings.Join(append(help, "enter: quit"), " • ")))
+            }
         }
 
     case stateError:
@@ -194,6 +574,23 @@ func (m Model) View() string {
     return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(parts, "\n"))
 }
 
+// Describe how the commands exited
+func (m Model) runStatus() string {
+	if m.runErr == nil {
+		return styles.SuccessStyle.Render("✓ Exited with status 0")
+	}
+	var exitErr *exec.ExitError
+	if errors.As(m.runErr, &exitErr) {
+		return styles.ErrorStyle.Render(fmt.Sprintf("✗ Exited with status %d", exitErr.ExitCode()))
+	}
+	return styles.ErrorStyle.Render(fmt.Sprintf("Error: failed to run: %v", m.runErr))
+}
+
+// Check if the reasoning summary should be displayed
+func (m Model) showReasoning() bool {
+	return m.opts.ShowReasoning && m.response != nil && m.response.Reasoning != ""
+}
+
 // Check if model is in the done state
 func (m Model) ShouldQuit() bool {
 	return m.state == stateDone
@@ -204,14 +601,39 @@ func (m Model) GetError() error {
 	return m.err
 }
 
-// Start UI and handle exit
-func Run(question string, provider ai.Provider, sysInfo *system.SystemInfo) error {
-	m := NewModel(question, provider, sysInfo)
-	p := tea.NewProgram(m)
+// Get the AI response, if one was received
+func (m Model) GetResponse() *ai.Response {
+	return m.response
+}
+
+// Start UI and handle exit, or print the answer for non-interactive formats.
+// Returns the response that was displayed
+func Run(request *ai.Request, provider ai.Provider, opts Options) (*ai.Response, error) {
+	if opts.Format != FormatTUI {
+		return runPlain(request, provider, opts)
+	}
+
+	m := NewModel(request, provider, opts)
+
+	var programOpts []tea.ProgramOption
+	if opts.InputTTY || opts.Print {
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	// Render on the terminal directly, leaving stdout for the chosen commands
+	if opts.Print {
+		tty, err := openTTY()
+		if err != nil {
+			return nil, err
+		}
+		defer tty.Close()
+		lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(tty))
+		programOpts = append(programOpts, tea.WithOutput(tty))
+	}
+	p := tea.NewProgram(m, programOpts...)
 
 	finalModel, err := p.Run()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// If there was an error, we already displayed it in the UI, so return nil
@@ -220,7 +642,24 @@ func Run(question string, provider ai.Provider, sysInfo *system.SystemInfo) erro
 		if finalModel.GetError() != nil {
 			os.Exit(1)
 		}
+		if opts.Print && finalModel.chosen {
+			fmt.Println(strings.Join(finalModel.response.Commands, "\n"))
+		}
+		return finalModel.GetResponse(), nil
 	}
 
-	return nil
+	return nil, nil
+}
+
+// Open the terminal for output when stdout is captured
+func openTTY() (*os.File, error) {
+	path := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		path = "CONOUT$"
+	}
+	tty, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open terminal: %w", err)
+	}
+	return tty, nil
 }
@@ -0,0 +1,149 @@
+package question
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/shell"
+	"github.com/connorgannaway/how/internal/ui/styles"
+)
+
+var (
+	portPattern = regexp.MustCompile(`\b([0-9]{2,5})\b`)
+	urlPattern  = regexp.MustCompile(`\bhttps?://\S+`)
+	pathPattern = regexp.MustCompile(`(?:^|\s)((?:~|\.{1,2})?/[\w./-]+|[\w-]+\.[A-Za-z0-9]{1,5})\b`)
+)
+
+// Words that never follow a keyword as its value, e.g. "the" in "branch the"
+var defaultStopWords = []string{"a", "an", "the", "to", "in", "on", "of", "for", "with", "from", "and", "or", "is", "that", "this", "my"}
+
+// Guess a placeholder's value from the question, e.g. 8080 for <PORT>
+// in "kill the process on port 8080". Returns "" when nothing fits
+func inferDefault(name, question string) string {
+	lowerName := strings.ToLower(name)
+	switch {
+	case strings.Contains(lowerName, "port"):
+		if match := portPattern.FindStringSubmatch(question); match != nil {
+			return match[1]
+		}
+	case strings.Contains(lowerName, "url"):
+		if match := urlPattern.FindString(question); match != "" {
+			return strings.TrimRight(match, ".,?!")
+		}
+	case strings.Contains(lowerName, "path"), strings.Contains(lowerName, "dir"), strings.Contains(lowerName, "file"), strings.Contains(lowerName, "folder"):
+		if match := pathPattern.FindStringSubmatch(question); match != nil {
+			return match[1]
+		}
+	}
+
+	// Otherwise take the word after a keyword from the name, e.g. "branch main"
+	words := strings.Fields(strings.ToLower(question))
+	original := strings.Fields(question)
+	for _, keyword := range strings.FieldsFunc(lowerName, func(r rune) bool { return r == '_' || r == '-' || r == ' ' || r == '/' }) {
+		if keyword == "your" || keyword == "name" || keyword == "path" || keyword == "to" {
+			continue
+		}
+		for i, word := range words[:max(len(words)-1, 0)] {
+			if strings.Trim(word, ".,?!'\"") != keyword {
+				continue
+			}
+			next := strings.Trim(original[i+1], ".,?!'\"")
+			if next != "" && !slices.Contains(defaultStopWords, strings.ToLower(next)) {
+				return next
+			}
+		}
+	}
+	return ""
+}
+
+// Open a form with an input per placeholder, filled with inferred defaults
+func (m Model) startFilling() (Model, tea.Cmd) {
+	m.filling = true
+	m.placeholderFocus = 0
+	m.placeholderInputs = make([]textinput.Model, len(m.response.Placeholders))
+	for i, placeholder := range m.response.Placeholders {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder.Name
+		input.CharLimit = 0
+		input.SetValue(inferDefault(placeholder.Name, m.request.Question))
+		m.placeholderInputs[i] = input
+	}
+	return m, m.placeholderInputs[0].Focus()
+}
+
+// Handle messages while filling placeholders
+func (m Model) updateFilling(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			m.state = stateDone
+			return m, tea.Quit
+		case "esc":
+			// Keep the placeholders as they are
+			m.filling = false
+			return m.present()
+		case "tab", "down":
+			return m.focusPlaceholder(m.placeholderFocus + 1)
+		case "shift+tab", "up":
+			return m.focusPlaceholder(m.placeholderFocus - 1)
+		case "enter":
+			if m.placeholderFocus < len(m.placeholderInputs)-1 {
+				return m.focusPlaceholder(m.placeholderFocus + 1)
+			}
+			return m.submitPlaceholders()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.placeholderInputs[m.placeholderFocus], cmd = m.placeholderInputs[m.placeholderFocus].Update(msg)
+	return m, cmd
+}
+
+// Move focus to another input, wrapping around
+func (m Model) focusPlaceholder(index int) (Model, tea.Cmd) {
+	m.placeholderInputs[m.placeholderFocus].Blur()
+	m.placeholderFocus = (index + len(m.placeholderInputs)) % len(m.placeholderInputs)
+	return m, m.placeholderInputs[m.placeholderFocus].Focus()
+}
+
+// Fill the placeholders into the commands and check them again
+func (m Model) submitPlaceholders() (tea.Model, tea.Cmd) {
+	m.filling = false
+
+	values := make(map[string]string)
+	var remaining []ai.Placeholder
+	for i, placeholder := range m.response.Placeholders {
+		value := strings.TrimSpace(m.placeholderInputs[i].Value())
+		if value == "" {
+			remaining = append(remaining, placeholder)
+			continue
+		}
+		values[placeholder.Token] = value
+	}
+
+	m.response.Commands = ai.FillPlaceholders(m.response.Commands, values)
+	m.response.Placeholders = remaining
+	m.missing = findMissingExecutables(m.response.Commands, m.request.SysInfo)
+	m.syntaxErrors = findSyntaxErrors(m.response.Commands, m.request.SysInfo.Shell)
+	m.risk = shell.AnalyzeAll(m.response.Commands, m.request.SysInfo.Shell)
+	return m.present()
+}
+
+// Render the placeholder form with its key help
+func (m Model) fillView() string {
+	var lines []string
+	for i, placeholder := range m.response.Placeholders {
+		label := styles.MutedStyle.Render(placeholder.Token)
+		if i == m.placeholderFocus {
+			label = styles.NoticeStyle.Render(placeholder.Token)
+		}
+		lines = append(lines, label+"  "+m.placeholderInputs[i].View())
+	}
+	lines = append(lines, styles.HelpStyle.MarginLeft(0).Render("tab: next • enter: fill • esc: skip"))
+	return strings.Join(lines, "\n")
+}
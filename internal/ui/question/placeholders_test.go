@@ -0,0 +1,27 @@
+package question
+
+import "testing"
+
+func TestInferDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		want     string
+	}{
+		{"PORT", "kill the process on port 8080", "8080"},
+		{"URL", "download https://example.com/file.tar.gz.", "https://example.com/file.tar.gz"},
+		{"/path/to/dir", "how big is ~/Downloads", "~/Downloads"},
+		{"FILE", "count lines in main.go", "main.go"},
+		{"BRANCH", "push branch feature-x to origin", "feature-x"},
+		{"branch name", "delete the branch the", ""},
+		{"HOST", "ssh into a server", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := inferDefault(test.name, test.question); got != test.want {
+				t.Errorf("inferDefault(%q, %q) = %q, want %q", test.name, test.question, got, test.want)
+			}
+		})
+	}
+}
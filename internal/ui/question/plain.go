@@ -0,0 +1,143 @@
+package question
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/shell"
+)
+
+// How an answer is shown
+type Format int
+
+const (
+	FormatTUI   Format = iota // Interactive UI
+	FormatPlain               // Title, description and commands as plain text, for when stdout is not a terminal
+	FormatJSON                // The full response with provider, model and usage
+	FormatRaw                 // The model's text as returned
+	FormatQuiet               // Only the commands, one per line
+)
+
+// Answer printed by --json
+type jsonOutput struct {
+	Question string `json:"question"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	ai.ResponseJSON
+	Risk     shell.Assessment `json:"risk"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// Ask without the UI and print the answer to stdout in the given format
+func runPlain(request *ai.Request, provider ai.Provider, opts Options) (*ai.Response, error) {
+	response := opts.Stored
+	var syntaxErrors []error
+	if response != nil {
+		syntaxErrors = findSyntaxErrors(response.Commands, request.SysInfo.Shell)
+	} else {
+		ctx := context.Background()
+		var err error
+		response, err = provider.Ask(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		// Retry once when a command does not parse, as the UI does
+		syntaxErrors = findSyntaxErrors(response.Commands, request.SysInfo.Shell)
+		if syntaxErrors != nil {
+			response, syntaxErrors = askCorrection(ctx, provider, request, response, syntaxErrors)
+		}
+	}
+
+	risk := shell.AnalyzeAll(response.Commands, request.SysInfo.Shell)
+	warnings := checkWarnings(response, syntaxErrors, findMissingExecutables(response.Commands, request.SysInfo), risk, request.SysInfo.Shell)
+
+	// JSON carries the warnings, other formats keep stdout for the answer
+	if opts.Format != FormatJSON {
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+
+	// Raw and quiet output is often piped into a shell, so high-risk
+	// commands are only printed when forced
+	if (opts.Format == FormatRaw || opts.Format == FormatQuiet) && risk.IsHighRisk() && !opts.Force {
+		return nil, fmt.Errorf("refusing to print a %s command without --force: %s", risk.Risk, risk.Reason)
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		output := jsonOutput{
+			Question:     request.Question,
+			Model:        opts.Model,
+			ResponseJSON: response.JSON(),
+			Risk:         risk,
+			Warnings:     warnings,
+		}
+		if provider != nil {
+			output.Provider = provider.GetName()
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(output); err != nil {
+			return nil, err
+		}
+
+	case FormatRaw:
+		fmt.Println(strings.TrimRight(response.RawResponse, "\n"))
+
+	case FormatQuiet:
+		if len(response.Commands) > 0 {
+			fmt.Println(strings.Join(response.Commands, "\n"))
+		}
+
+	default:
+		printPlain(os.Stdout, response, opts.ShowReasoning)
+	}
+	return response, nil
+}
+
+// Print an answer the way the UI lays it out, without styling
+func printPlain(w io.Writer, response *ai.Response, showReasoning bool) {
+	if response.Title != "" {
+		fmt.Fprintln(w, response.Title)
+	}
+	if response.Description != "" {
+		fmt.Fprintln(w, response.Description)
+	}
+	for _, command := range response.Commands {
+		fmt.Fprintf(w, "\n%s\n", command)
+	}
+	if showReasoning && response.Reasoning != "" {
+		fmt.Fprintf(w, "\nReasoning:\n%s\n", response.Reasoning)
+	}
+}
+
+// Describe the problems the UI would show as notices
+func checkWarnings(response *ai.Response, syntaxErrors []error, missing []missingExecutable, risk shell.Assessment, shellName string) []string {
+	var warnings []string
+	if risk.Risk != shell.RiskSafe {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", risk.Risk, risk.Reason))
+	}
+	for i, err := range syntaxErrors {
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("command %d: invalid %s syntax: %v", i+1, shellName, err))
+		}
+	}
+	for _, executable := range missing {
+		warning := executable.name + " is not installed"
+		if executable.installHint != "" {
+			warning += ", install with: " + executable.installHint
+		}
+		warnings = append(warnings, warning)
+	}
+	for _, placeholder := range response.Placeholders {
+		warnings = append(warnings, fmt.Sprintf("fill in %s before running", placeholder.Token))
+	}
+	return warnings
+}
@@ -0,0 +1,107 @@
+package question
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/system"
+)
+
+// Provider that always returns the same answer
+type fixedProvider struct {
+	response ai.Response
+}
+
+func (p *fixedProvider) Ask(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	response := p.response
+	return &response, nil
+}
+
+func (p *fixedProvider) GetName() string {
+	return "test"
+}
+
+// Run f with stdout and stderr captured
+func capture(t *testing.T, f func()) (string, string) {
+	t.Helper()
+	read := func(file **os.File) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		original := *file
+		*file = w
+		done := make(chan string)
+		go func() {
+			data, _ := io.ReadAll(r)
+			done <- string(data)
+		}()
+		return func() string {
+			w.Close()
+			*file = original
+			return <-done
+		}
+	}
+	stdout, stderr := read(&os.Stdout), read(&os.Stderr)
+	f()
+	return stdout(), stderr()
+}
+
+func TestRunPlain(t *testing.T) {
+	request := func() *ai.Request {
+		return &ai.Request{Question: "q", SysInfo: &system.SystemInfo{OS: "linux", Shell: "bash"}}
+	}
+	safe := ai.Response{Title: "List files", Commands: []string{"ls -la"}, RawResponse: "COMMAND: ls -la"}
+	destructive := ai.Response{Title: "Wipe", Commands: []string{"rm -rf /"}, RawResponse: "COMMAND: rm -rf /"}
+	placeholder := ai.Response{Commands: []string{"cd <DIR>"}, Placeholders: []ai.Placeholder{{Token: "<DIR>", Name: "DIR"}}}
+
+	tests := []struct {
+		name       string
+		response   ai.Response
+		opts       Options
+		wantErr    bool
+		wantStdout string
+		wantStderr string
+	}{
+		{"quiet", safe, Options{Format: FormatQuiet}, false, "ls -la\n", ""},
+		{"raw", safe, Options{Format: FormatRaw}, false, "COMMAND: ls -la\n", ""},
+		{"quiet refuses high risk", destructive, Options{Format: FormatQuiet}, true, "", "Warning: destructive"},
+		{"raw refuses high risk", destructive, Options{Format: FormatRaw}, true, "", "Warning: destructive"},
+		{"quiet forced", destructive, Options{Format: FormatQuiet, Force: true}, false, "rm -rf /\n", "Warning: destructive"},
+		{"plain prints high risk", destructive, Options{Format: FormatPlain}, false, "Wipe\n\nrm -rf /\n", "Warning: destructive"},
+		{"quiet warns", placeholder, Options{Format: FormatQuiet}, false, "cd <DIR>\n", "Warning: fill in <DIR>"},
+		{"json keeps stderr empty", destructive, Options{Format: FormatJSON}, false, `"reason": "Deletes a system or home directory"`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var err error
+			stdout, stderr := capture(t, func() {
+				_, err = runPlain(request(), &fixedProvider{response: test.response}, test.opts)
+			})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("runPlain() error = %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr && stdout != "" {
+				t.Errorf("refused answer printed to stdout: %q", stdout)
+			}
+			if test.opts.Format == FormatJSON {
+				if !strings.Contains(stdout, test.wantStdout) {
+					t.Errorf("stdout = %q, want it to contain %q", stdout, test.wantStdout)
+				}
+			} else if stdout != test.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout, test.wantStdout)
+			}
+			if test.wantStderr == "" && stderr != "" {
+				t.Errorf("stderr = %q, want none", stderr)
+			}
+			if !strings.Contains(stderr, test.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr, test.wantStderr)
+			}
+		})
+	}
+}
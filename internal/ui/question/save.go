@@ -0,0 +1,123 @@
+package question
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/connorgannaway/how/internal/saved"
+	"github.com/connorgannaway/how/internal/ui/styles"
+)
+
+type savedMsg struct {
+	name string
+	err  error
+}
+
+// Open a form for the snippet's name and tags, named after the title by default
+func (m Model) startSaving() (Model, tea.Cmd) {
+	m.saving = true
+	m.saveErr = nil
+	m.saveFocus = 0
+
+	name := textinput.New()
+	name.Prompt = ""
+	name.Placeholder = "name"
+	name.CharLimit = 64
+	name.SetValue(m.opts.StoredName)
+	if m.opts.StoredName == "" {
+		name.SetValue(saved.Slugify(m.response.Title))
+	}
+
+	tags := textinput.New()
+	tags.Prompt = ""
+	tags.Placeholder = "tags, comma separated"
+	tags.CharLimit = 0
+	tags.SetValue(strings.Join(m.opts.StoredTags, ", "))
+
+	m.saveInputs = []textinput.Model{name, tags}
+	return m, m.saveInputs[0].Focus()
+}
+
+// Handle messages while naming the snippet
+func (m Model) updateSaving(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			m.state = stateDone
+			return m, tea.Quit
+		case "esc":
+			return m.finishSaving()
+		case "tab", "shift+tab", "up", "down":
+			return m.focusSaveInput(1 - m.saveFocus)
+		case "enter":
+			if m.saveFocus == 0 {
+				return m.focusSaveInput(1)
+			}
+			return m.submitSave()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.saveInputs[m.saveFocus], cmd = m.saveInputs[m.saveFocus].Update(msg)
+	return m, cmd
+}
+
+// Move focus between the name and tags inputs
+func (m Model) focusSaveInput(index int) (Model, tea.Cmd) {
+	m.saveInputs[m.saveFocus].Blur()
+	m.saveFocus = index
+	return m, m.saveInputs[m.saveFocus].Focus()
+}
+
+// Save the commands as shown, including any edits and filled placeholders
+func (m Model) submitSave() (tea.Model, tea.Cmd) {
+	name := strings.TrimPrefix(strings.TrimSpace(m.saveInputs[0].Value()), "@")
+	if err := saved.ValidateName(name); err != nil {
+		m.saveErr = err
+		return m.focusSaveInput(0)
+	}
+
+	response := *m.response
+	snippet := saved.Snippet{
+		Name:     name,
+		Tags:     saved.ParseTags(m.saveInputs[1].Value()),
+		Question: m.request.Question,
+		OS:       m.request.SysInfo.OSName,
+		Shell:    m.request.SysInfo.Shell,
+		Response: &response,
+		Created:  time.Now(),
+	}
+	return m, func() tea.Msg {
+		return savedMsg{name: name, err: saved.Add(snippet)}
+	}
+}
+
+// Close the form, carrying on as if it had not been opened by --save
+func (m Model) finishSaving() (tea.Model, tea.Cmd) {
+	m.saving = false
+	if m.opts.Save {
+		m.opts.Save = false
+		return m.present()
+	}
+	return m, nil
+}
+
+// Render the save form with its key help
+func (m Model) saveView() string {
+	labels := []string{"Name", "Tags"}
+	var lines []string
+	for i, input := range m.saveInputs {
+		label := styles.MutedStyle.Render(labels[i])
+		if i == m.saveFocus {
+			label = styles.NoticeStyle.Render(labels[i])
+		}
+		lines = append(lines, label+"  "+input.View())
+	}
+	if m.saveErr != nil {
+		lines = append(lines, styles.ErrorStyle.Render("Error: "+m.saveErr.Error()))
+	}
+	lines = append(lines, styles.HelpStyle.MarginLeft(0).Render("tab: next • enter: save • esc: cancel"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,373 @@
+package saved
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/clipboard"
+	"github.com/connorgannaway/how/internal/saved"
+	"github.com/connorgannaway/how/internal/ui/styles"
+)
+
+type state int
+
+const (
+	stateList state = iota
+	stateDetail
+	stateEditing
+	stateDone
+)
+
+// Bubbletea model for browsing saved snippets
+type Model struct {
+	state    state
+	list     list.Model
+	snippets []saved.Snippet
+	selected string // Name of the snippet being shown or edited
+	editor   textarea.Model
+	deleting bool // Waiting for y/n before deleting the selected snippet
+	err      error
+	copied   bool
+	width    int
+	height   int
+}
+
+// Item for list
+type item struct {
+	snippet saved.Snippet
+}
+
+func (i item) Title() string { return "@" + i.snippet.Name }
+
+func (i item) Description() string {
+	var command string
+	if len(i.snippet.Response.Commands) > 0 {
+		command, _, _ = strings.Cut(i.snippet.Response.Commands[0], "\n")
+	}
+	if len(i.snippet.Tags) > 0 {
+		return fmt.Sprintf("#%s · %s", strings.Join(i.snippet.Tags, " #"), command)
+	}
+	return command
+}
+
+// Search names, tags, questions and commands
+func (i item) FilterValue() string {
+	return strings.Join(append([]string{i.snippet.Name, strings.Join(i.snippet.Tags, " "), i.snippet.Question}, i.snippet.Response.Commands...), " ")
+}
+
+type clipboardMsg struct {
+	success bool
+}
+
+// Create model for saved snippets UI, sorted by name
+func NewModel(snippets []saved.Snippet) Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.SetSpacing(0)
+
+	snippetList := list.New(nil, delegate, 0, 0)
+	snippetList.Title = "Saved"
+	snippetList.SetShowHelp(false)
+	snippetList.SetStatusBarItemName("snippet", "snippets")
+
+	m := Model{
+		state:    stateList,
+		list:     snippetList,
+		snippets: snippets,
+	}
+	m.refreshItems()
+	return m
+}
+
+// Rebuild the list after snippets change
+func (m *Model) refreshItems() {
+	sorted := slices.Clone(m.snippets)
+	slices.SortFunc(sorted, func(a, b saved.Snippet) int { return strings.Compare(a.Name, b.Name) })
+	items := make([]list.Item, len(sorted))
+	for i, snippet := range sorted {
+		items[i] = item{snippet: snippet}
+	}
+	m.list.SetItems(items)
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.list.SetSize(msg.Width, msg.Height-2)
+		return m, nil
+
+	case clipboardMsg:
+		m.copied = msg.success
+		m.state = stateDone
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.state = stateDone
+			return m, tea.Quit
+		}
+
+		// Confirm or cancel deleting the selected snippet
+		if m.deleting {
+			switch msg.String() {
+			case "y":
+				return m.deleteSelected()
+			case "n", "esc":
+				m.deleting = false
+			}
+			return m, nil
+		}
+
+		switch m.state {
+		case stateList:
+			// Let the filter input handle keys while typing
+			if m.list.FilterState() == list.Filtering {
+				break
+			}
+			selected, ok := m.list.SelectedItem().(item)
+			switch msg.String() {
+			case "q", "esc":
+				if m.list.FilterState() == list.FilterApplied {
+					m.list.ResetFilter()
+					return m, nil
+				}
+				m.state = stateDone
+				return m, tea.Quit
+			case "enter":
+				if ok {
+					m.selected = selected.snippet.Name
+					m.state = stateDetail
+				}
+				return m, nil
+			case "c":
+				if ok {
+					return m, copyToClipboard(selected.snippet.Response.Commands)
+				}
+				return m, nil
+			case "e":
+				if ok {
+					m.selected = selected.snippet.Name
+					return m.startEditing()
+				}
+				return m, nil
+			case "d":
+				if ok {
+					m.selected = selected.snippet.Name
+					m.deleting = true
+				}
+				return m, nil
+			}
+
+		case stateDetail:
+			switch msg.String() {
+			case "q":
+				m.state = stateDone
+				return m, tea.Quit
+			case "esc", "backspace":
+				m.state = stateList
+			case "c":
+				return m, copyToClipboard(m.snippet().Response.Commands)
+			case "e":
+				return m.startEditing()
+			case "d":
+				m.deleting = true
+			}
+			return m, nil
+
+		case stateEditing:
+			switch msg.String() {
+			case "esc":
+				m.state = stateDetail
+				return m, nil
+			case "ctrl+s":
+				return m.saveEdit()
+			}
+			var cmd tea.Cmd
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.state == stateEditing {
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// Get the selected snippet
+func (m Model) snippet() *saved.Snippet {
+	return saved.Find(m.snippets, m.selected)
+}
+
+// Load the selected snippet's commands into an editor, one per line
+func (m Model) startEditing() (Model, tea.Cmd) {
+	text := strings.Join(m.snippet().Response.Commands, "\n")
+	m.state = stateEditing
+	m.err = nil
+	m.editor = textarea.New()
+	m.editor.ShowLineNumbers = false
+	m.editor.Prompt = ""
+	m.editor.CharLimit = 0
+	m.editor.SetWidth(styles.DefaultMaxWidth)
+	m.editor.SetHeight(min(strings.Count(text, "\n")+2, 15))
+	m.editor.SetValue(text)
+	return m, m.editor.Focus()
+}
+
+// Replace the selected snippet's commands with the edited text
+func (m Model) saveEdit() (tea.Model, tea.Cmd) {
+	snippet := m.snippet()
+
+	var commands []string
+	if strings.Contains(strings.Join(snippet.Response.Commands, ""), "\n") {
+		// Keep scripts as a single script
+		if text := strings.TrimSpace(m.editor.Value()); text != "" {
+			commands = []string{text}
+		}
+	} else {
+		for _, line := range strings.Split(m.editor.Value(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commands = append(commands, line)
+			}
+		}
+	}
+	if len(commands) == 0 {
+		return m, nil
+	}
+
+	response := *snippet.Response
+	response.Commands = commands
+	response.Placeholders = ai.FindPlaceholders(commands)
+	snippet.Response = &response
+	if m.err = saved.Save(m.snippets); m.err != nil {
+		return m, nil
+	}
+	m.refreshItems()
+	m.state = stateDetail
+	return m, nil
+}
+
+// Delete the selected snippet and go back to the list
+func (m Model) deleteSelected() (tea.Model, tea.Cmd) {
+	m.deleting = false
+	if m.err = saved.Delete(m.selected); m.err != nil {
+		return m, nil
+	}
+	m.snippets = slices.DeleteFunc(m.snippets, func(s saved.Snippet) bool { return s.Name == m.selected })
+	m.refreshItems()
+	m.state = stateList
+	return m, nil
+}
+
+func (m Model) View() string {
+	var footer string
+	switch {
+	case m.err != nil:
+		footer = styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+	case m.deleting:
+		footer = styles.NoticeStyle.Render(fmt.Sprintf("Delete @%s? ", m.selected)) + styles.MutedStyle.Render("y: delete • n: cancel")
+	}
+
+	switch m.state {
+	case stateList:
+		help := styles.HelpStyle.Render("/: search • enter: show • c: copy • e: edit • d: delete • q: quit")
+		if footer != "" {
+			help = lipgloss.NewStyle().MarginLeft(2).Render(footer)
+		}
+		return m.list.View() + "\n" + help
+
+	case stateDetail, stateEditing:
+		view := m.detailView()
+		if footer != "" {
+			view += "\n" + footer
+		}
+		return lipgloss.NewStyle().Padding(1, 2).Render(view)
+	}
+	return ""
+}
+
+// Render a snippet with its commands, or the editor while editing
+func (m Model) detailView() string {
+	width := styles.DefaultMaxWidth
+	if m.width > 0 && m.width-4 < width {
+		width = m.width - 4
+	}
+
+	snippet := m.snippet()
+	response := snippet.Response
+	var parts []string
+	parts = append(parts, styles.QuestionStyle.Width(width).Render("★ @"+snippet.Name))
+
+	meta := ai.FormatQuestion(snippet.Question)
+	if len(snippet.Tags) > 0 {
+		meta += " · #" + strings.Join(snippet.Tags, " #")
+	}
+	if snippet.OS != "" {
+		meta += fmt.Sprintf(" · %s, %s", snippet.OS, snippet.Shell)
+	}
+	parts = append(parts, styles.MutedStyle.Width(width).Render(meta), "")
+
+	if response.Title != "" {
+		parts = append(parts, styles.TitleStyle.Width(width).Render(response.Title))
+	}
+	if response.Description != "" {
+		parts = append(parts, styles.DescriptionStyle.Width(width).Render(response.Description))
+	}
+	if m.state == stateEditing {
+		parts = append(parts, "", m.editor.View(), styles.HelpStyle.MarginLeft(0).Render("ctrl+s: save • esc: cancel"))
+		return strings.Join(parts, "\n")
+	}
+	for _, cmd := range response.Commands {
+		// Don't render prompt symbol for potential scripts
+		if strings.Contains(cmd, "\n") {
+			parts = append(parts, styles.CommandStyle.Width(width).Render(cmd))
+		} else {
+			parts = append(parts, styles.CommandStyle.Width(width).Render(styles.PromptSymbol+cmd))
+		}
+	}
+	parts = append(parts, styles.HelpStyle.MarginLeft(0).Render("c: copy • e: edit • d: delete • esc: back • q: quit"))
+	return strings.Join(parts, "\n")
+}
+
+// clipboard wrapper for usage with tea commands
+func copyToClipboard(commands []string) tea.Cmd {
+	return func() tea.Msg {
+		err := clipboard.CopyCommands(commands)
+		return clipboardMsg{success: err == nil}
+	}
+}
+
+// Start UI
+func Run(snippets []saved.Snippet) error {
+	if len(snippets) == 0 {
+		fmt.Println("No saved snippets yet. Run how --save with a question to save its answer.")
+		return nil
+	}
+
+	p := tea.NewProgram(NewModel(snippets), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	if finalModel, ok := finalModel.(Model); ok && finalModel.copied {
+		fmt.Println(styles.SuccessStyle.Render("✓ Copied to clipboard"))
+	}
+	return nil
+}
@@ -84,6 +84,24 @@ func Run(cfg *config.Config, showKey, showAll, revealFull bool) {
 		lines = append(lines, baseURLLine)
 	}
 
+	// Vertex AI project and location for Google
+	if cfg.CurrentProvider == config.ProviderGoogle && cfg.Google.Vertex {
+		vertexLine := fmt.Sprintf("%s %s",
+			labelStyle.Render("Vertex AI:"),
+			valueStyle.Render(cfg.Google.Project+" ("+cfg.Google.Location+")"),
+		)
+		lines = append(lines, vertexLine)
+	}
+
+	// Generation parameters, if any are configured
+	if params := cfg.ResolveParameters(cfg.CurrentProvider, cfg.CurrentModel); !params.IsEmpty() {
+		paramsLine := fmt.Sprintf("%s %s",
+			labelStyle.Render("Params:"),
+			valueStyle.Render(params.String()),
+		)
+		lines = append(lines, paramsLine)
+	}
+
 	// Show API key(s)
 	if showKey {
 		if showAll {
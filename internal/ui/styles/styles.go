@@ -37,10 +37,25 @@ var (
 				Foreground(Success).
 				Render("$ ")
 
+	ReasoningStyle = lipgloss.NewStyle().
+			Foreground(Muted).
+			Italic(true).
+			PaddingLeft(2).
+			MarginBottom(1)
+
 	ErrorStyle = lipgloss.NewStyle().
 			Foreground(Error).
 			Bold(true)
 
+	NoticeStyle = lipgloss.NewStyle().
+			Foreground(Warning)
+
+	DangerBannerStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(White).
+				Background(Error).
+				Padding(0, 1)
+
 	SuccessStyle = lipgloss.NewStyle().
 			Foreground(Success)
 
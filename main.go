@@ -1,22 +1,55 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/connorgannaway/how/internal/ai"
+	"github.com/connorgannaway/how/internal/batch"
+	"github.com/connorgannaway/how/internal/cache"
 	"github.com/connorgannaway/how/internal/config"
+	"github.com/connorgannaway/how/internal/gateway"
+	"github.com/connorgannaway/how/internal/history"
+	"github.com/connorgannaway/how/internal/input"
+	"github.com/connorgannaway/how/internal/mcp"
+	"github.com/connorgannaway/how/internal/saved"
+	"github.com/connorgannaway/how/internal/server"
+	"github.com/connorgannaway/how/internal/shell"
 	"github.com/connorgannaway/how/internal/system"
 	"github.com/connorgannaway/how/internal/ui/clear"
 	"github.com/connorgannaway/how/internal/ui/configure"
+	historyui "github.com/connorgannaway/how/internal/ui/history"
 	"github.com/connorgannaway/how/internal/ui/question"
+	savedui "github.com/connorgannaway/how/internal/ui/saved"
 	"github.com/connorgannaway/how/internal/ui/status"
 )
 
 var Version = "dev"
 
+// Commands run as "how <command> [options]"
+var commands = []string{"serve", "mcp", "gateway"}
+
+// Repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func main() {
 	// Define flags
 	versionFlag := flag.Bool("v", false, "Print version and exit")
@@ -32,13 +65,45 @@ func main() {
 	clearLongFlag := flag.Bool("clear", false, "Clear API keys from configuration")
 	allFlag := flag.Bool("a", false, "With --status --key: show all provider API keys. With --clear: clear all API keys without prompting")
 	allLongFlag := flag.Bool("all", false, "With --status --key: show all provider API keys. With --clear: clear all API keys without prompting")
+	temperatureFlag := flag.Float64("temperature", 0, "Sampling temperature for this request")
+	maxTokensFlag := flag.Int64("max-tokens", 0, "Maximum output tokens for this request")
+	reasoningFlag := flag.String("reasoning", "", "Reasoning level for this request: off, low, medium, high")
+	continueFlag := flag.Bool("continue", false, "Follow up on the previous answer (OpenAI Responses API)")
+	projectFlag := flag.Bool("project", false, "Include the project in the current directory as context")
+	runFlag := flag.Bool("run", false, "Ask to run the answer in your shell after it is shown")
+	editFlag := flag.Bool("e", false, "Edit the answer before it is copied")
+	editLongFlag := flag.Bool("edit", false, "Edit the answer before it is copied")
+	printFlag := flag.Bool("print", false, "Show the UI on the terminal and print the chosen command to stdout")
+	initFlag := flag.String("init", "", "Print the shell integration script for bash, zsh, fish, nu or pwsh")
+	historyFlag := flag.Bool("history", false, "Search past questions and answers")
+	noCacheFlag := flag.Bool("no-cache", false, "Ask the provider even if the response is cached")
+	cacheFlag := flag.String("cache", "", "Manage the response cache: clear, stats")
+	saveFlag := flag.Bool("save", false, "Name the answer and save it as a snippet")
+	savedFlag := flag.Bool("saved", false, "Search saved snippets to copy, edit or delete")
+	jsonFlag := flag.Bool("json", false, "Print the answer as JSON instead of showing the UI")
+	rawFlag := flag.Bool("raw", false, "Print the model's text instead of showing the UI")
+	quietFlag := flag.Bool("q", false, "Print only the commands instead of showing the UI")
+	quietLongFlag := flag.Bool("quiet", false, "Print only the commands instead of showing the UI")
+	forceFlag := flag.Bool("force", false, "Print high-risk commands with --raw or --quiet")
+	batchFlag := flag.String("batch", "", "Ask each question in a text or JSONL file and print JSONL results")
+	concurrencyFlag := flag.Int("concurrency", 4, "Questions asked at once with --batch")
+	rateFlag := flag.Int("rate", 0, "Requests per minute with --batch, 0 for no limit")
+	listenFlag := flag.String("listen", "127.0.0.1:0", "Address for serve to listen on")
+	var fileFlags stringList
+	flag.Var(&fileFlags, "f", "Attach a file as context, optionally with a line range (path[:start-end])")
+	flag.Var(&fileFlags, "file", "Attach a file as context, optionally with a line range (path[:start-end])")
 	helpFlag := flag.Bool("h", false, "Show help message")
 	helpLongFlag := flag.Bool("help", false, "Show help message")
 
 	// Custom usage function
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: how [options] <question>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: how [options] <question>\n")
+		fmt.Fprintf(os.Stderr, "       how <command> [options]\n\n")
 		fmt.Fprintf(os.Stderr, "AI-powered terminal command assistant\n\n")
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  serve              Serve ask, explain and history over HTTP for editor plugins\n")
+		fmt.Fprintf(os.Stderr, "  mcp                Run a Model Context Protocol server on stdio for agents and IDEs\n")
+		fmt.Fprintf(os.Stderr, "  gateway            Run an OpenAI-compatible endpoint with per-user tokens and quotas\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -c, --configure    Configure AI provider and API key\n")
 		fmt.Fprintf(os.Stderr, "  -s, --status       Show current configuration status\n")
@@ -46,11 +111,46 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  --reveal-full      Show full unmasked API keys (use with --status --key)\n")
 		fmt.Fprintf(os.Stderr, "  -r, --clear        Clear API keys from configuration\n")
 		fmt.Fprintf(os.Stderr, "  -a, --all          Use with --status/--clear for all providers\n")
+		fmt.Fprintf(os.Stderr, "  --temperature N    Override sampling temperature (0-2)\n")
+		fmt.Fprintf(os.Stderr, "  --max-tokens N     Override maximum output tokens\n")
+		fmt.Fprintf(os.Stderr, "  --reasoning LEVEL  Override reasoning level (off, low, medium, high)\n")
+		fmt.Fprintf(os.Stderr, "  --continue         Follow up on the previous answer (OpenAI Responses API)\n")
+		fmt.Fprintf(os.Stderr, "  -f, --file PATH    Attach a file as context, repeatable (path[:start-end])\n")
+		fmt.Fprintf(os.Stderr, "  --project          Include the project in the current directory as context\n")
+		fmt.Fprintf(os.Stderr, "  --run              Ask to run the answer in your shell after it is shown\n")
+		fmt.Fprintf(os.Stderr, "  -e, --edit         Edit the answer before it is copied\n")
+		fmt.Fprintf(os.Stderr, "  --print            Show the UI on the terminal and print the chosen command to stdout\n")
+		fmt.Fprintf(os.Stderr, "  --init SHELL       Print the shell integration script (bash, zsh, fish, nu, pwsh)\n")
+		fmt.Fprintf(os.Stderr, "  --history          Search past questions to show, copy or ask again\n")
+		fmt.Fprintf(os.Stderr, "  --no-cache         Ask the provider even if the response is cached\n")
+		fmt.Fprintf(os.Stderr, "  --cache ACTION     Manage the response cache (clear, stats)\n")
+		fmt.Fprintf(os.Stderr, "  --save             Name the answer and save it as a snippet\n")
+		fmt.Fprintf(os.Stderr, "  --saved            Search saved snippets to copy, edit or delete\n")
+		fmt.Fprintf(os.Stderr, "  @NAME              Recall a saved snippet without asking the provider\n")
+		fmt.Fprintf(os.Stderr, "  --json             Print the answer, provider, model and usage as JSON\n")
+		fmt.Fprintf(os.Stderr, "  --raw              Print the model's text as returned\n")
+		fmt.Fprintf(os.Stderr, "  -q, --quiet        Print only the commands, one per line\n")
+		fmt.Fprintf(os.Stderr, "  --force            Print high-risk commands with --raw or --quiet\n")
+		fmt.Fprintf(os.Stderr, "  --batch FILE       Ask each question in a text or JSONL file, printing JSONL results\n")
+		fmt.Fprintf(os.Stderr, "  --concurrency N    Questions asked at once with --batch (default 4)\n")
+		fmt.Fprintf(os.Stderr, "  --rate N           Requests per minute with --batch (default no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --listen ADDR      Address for serve to listen on (default 127.0.0.1 on a free port)\n")
 		fmt.Fprintf(os.Stderr, "  -v, --version      Print version and exit\n")
 		fmt.Fprintf(os.Stderr, "  -h, --help         Show this help message\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  how do I check if a process is listening on port 3000\n")
 		fmt.Fprintf(os.Stderr, "  how do I compress png images over 20MB in a folder\n")
+		fmt.Fprintf(os.Stderr, "  cat build.log | how fix this error\n")
+		fmt.Fprintf(os.Stderr, "  how --file Makefile do I add a target that runs the tests\n")
+		fmt.Fprintf(os.Stderr, "  how --temperature 0 how do I list open ports\n")
+		fmt.Fprintf(os.Stderr, "  how --run do I find files larger than 1GB\n")
+		fmt.Fprintf(os.Stderr, "  eval \"$(how --init zsh)\"\n")
+		fmt.Fprintf(os.Stderr, "  how --history\n")
+		fmt.Fprintf(os.Stderr, "  how --cache stats\n")
+		fmt.Fprintf(os.Stderr, "  how @kill-port\n")
+		fmt.Fprintf(os.Stderr, "  how --batch questions.jsonl --rate 30 > answers.jsonl\n")
+		fmt.Fprintf(os.Stderr, "  how serve --listen 127.0.0.1:8765\n")
+		fmt.Fprintf(os.Stderr, "  how gateway --add-user alice --daily-requests 200\n")
 		fmt.Fprintf(os.Stderr, "  how --configure\n")
 		fmt.Fprintf(os.Stderr, "  how --status\n")
 		fmt.Fprintf(os.Stderr, "  how --status --key\n")
@@ -60,6 +160,20 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  how --clear --all\n")
 	}
 
+	// A command name followed by nothing or flags runs that command, so
+	// questions such as "how serve a folder over http" still work
+	var command string
+	if len(os.Args) > 1 && slices.Contains(commands, os.Args[1]) && (len(os.Args) == 2 || strings.HasPrefix(os.Args[2], "-")) {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	// The gateway has its own flags
+	if command == "gateway" {
+		runGateway(os.Args[1:])
+		os.Exit(0)
+	}
+
 	flag.Parse()
 
 	// Handle version flag
@@ -74,6 +188,28 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Choose how to show the answer, falling back to plain text when stdout is not a terminal
+	format, err := outputFormat(*jsonFlag, *rawFlag, *quietFlag || *quietLongFlag, *printFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if format != question.FormatTUI && (*runFlag || *editFlag || *editLongFlag || *saveFlag) {
+		fmt.Fprintf(os.Stderr, "Error: --run, --edit and --save need the interactive UI\n")
+		os.Exit(1)
+	}
+
+	// Handle init flag
+	if *initFlag != "" {
+		script, err := shell.InitScript(*initFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
+		os.Exit(0)
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -128,6 +264,86 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle cache flag
+	if *cacheFlag != "" {
+		responseCache, err := openResponseCache(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening cache: %v\n", err)
+			os.Exit(1)
+		}
+		switch *cacheFlag {
+		case "clear":
+			cleared, err := responseCache.Clear()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error clearing cache: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ Cleared %d cached response(s)\n", cleared)
+		case "stats":
+			stats, err := responseCache.Stats()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading cache: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Responses: %d (%d expired)\n", stats.Entries, stats.Expired)
+			fmt.Printf("Size:      %.1f KB of %d MB\n", float64(stats.Bytes)/1024, cfg.Cache.GetMaxBytes()/1024/1024)
+			fmt.Printf("TTL:       %s\n", cfg.Cache.GetTTL())
+			if stats.Entries > 0 {
+				fmt.Printf("Oldest:    %s\n", stats.Oldest.Format("2006-01-02 15:04"))
+				fmt.Printf("Newest:    %s\n", stats.Newest.Format("2006-01-02 15:04"))
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown cache action: %s (use clear or stats)\n", *cacheFlag)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Handle history flag, which may pick a question to ask again
+	var questionText string
+	if *historyFlag {
+		entries, err := history.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading history: %v\n", err)
+			os.Exit(1)
+		}
+		questionText, err = historyui.Run(entries)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if questionText == "" {
+			os.Exit(0)
+		}
+	}
+
+	// Handle saved flag
+	if *savedFlag {
+		snippets, err := saved.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading saved snippets: %v\n", err)
+			os.Exit(1)
+		}
+		if err := savedui.Run(snippets); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Recall a saved snippet by name, without a provider
+	if args := flag.Args(); questionText == "" && len(args) == 1 && strings.HasPrefix(args[0], "@") {
+		recallSnippet(strings.TrimPrefix(args[0], "@"), question.Options{
+			InputTTY: input.StdinIsPiped(),
+			Run:      *runFlag,
+			Edit:     *editFlag || *editLongFlag,
+			Print:    *printFlag,
+			Save:     *saveFlag,
+			Format:   format,
+		})
+		os.Exit(0)
+	}
+
 	// Check if configured
 	if ready, missing := cfg.IsConfigured(); !ready {
 		fmt.Fprintf(os.Stderr, "Not configured. Missing: %v. Run 'how --configure' to set up.\n", strings.Join(missing, ", "))
@@ -135,12 +351,14 @@ func main() {
 	}
 
 	// Build question from arguments
-	args := flag.Args()
-	if len(args) == 0 {
-		flag.Usage()
-		os.Exit(1)
+	if questionText == "" && *batchFlag == "" && command == "" {
+		args := flag.Args()
+		if len(args) == 0 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		questionText = strings.Join(args, " ")
 	}
-	questionText := strings.Join(args, " ")
 
 	// Detect system
 	sysInfo, err := system.DetectSystem()
@@ -149,27 +367,497 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Retrieve API key
-	apiKey, err := config.GetAPIKeyFromKeyring(cfg.CurrentProvider)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error retrieving API key: %v\n", err)
+	// Probe installed CLI tools, cached between runs
+	tools := cfg.Tools
+	if len(tools) == 0 {
+		tools = system.DefaultTools
+	}
+	var toolCachePath string
+	if cacheDir, err := config.GetCacheDir(); err == nil {
+		toolCachePath = filepath.Join(cacheDir, "tools.json")
+	}
+	sysInfo.Tools = system.DetectTools(tools, toolCachePath)
+
+	// Detect project in the working directory
+	if *projectFlag || cfg.ProjectContext {
+		if cwd, err := os.Getwd(); err == nil {
+			sysInfo.Project = system.DetectProject(cwd)
+		}
+	}
+
+	// Resolve generation parameters, with flags taking precedence over config
+	params := cfg.ResolveParameters(cfg.CurrentProvider, cfg.CurrentModel)
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "temperature":
+			params.Temperature = temperatureFlag
+		case "max-tokens":
+			params.MaxTokens = maxTokensFlag
+		case "reasoning":
+			params.Reasoning = *reasoningFlag
+		}
+	})
+	if err := params.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parameters: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Create AI provider
-	provider, err := ai.NewProvider(
-		cfg.CurrentProvider,
-		apiKey,
-		cfg.CurrentModel,
-		cfg.BaseURL,
-	)
+	provider, err := newProvider(cfg, cfg.CurrentProvider, cfg.CurrentModel, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating AI provider: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Load state for follow-ups. Only needed by providers that can continue,
+	// and only fatal when following up
+	state := &config.State{}
+	continuable, canContinue := provider.(ai.ContinuableProvider)
+	if *continueFlag && !canContinue {
+		fmt.Fprintf(os.Stderr, "Follow-ups are not supported by %s\n", provider.GetName())
+		os.Exit(1)
+	}
+	if canContinue {
+		loaded, err := config.LoadState()
+		if err != nil && *continueFlag {
+			fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load state: %v\n", err)
+		} else {
+			state = loaded
+		}
+	}
+	if *continueFlag {
+		if state.LastResponseID == "" {
+			fmt.Fprintf(os.Stderr, "No previous answer to follow up on\n")
+			os.Exit(1)
+		}
+		if err := continuable.SetPreviousResponseID(state.LastResponseID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Reuse cached responses. Follow-ups depend on server-side state, so are never cached
+	if !*noCacheFlag && !*continueFlag && !cfg.Cache.Disabled {
+		if responseCache, err := openResponseCache(cfg); err == nil {
+			provider = ai.NewCachedProvider(provider, responseCache, cfg.CurrentModel, cfg.BaseURL, providerOptions(cfg, cfg.CurrentModel, params))
+		}
+	}
+
+	// Serve the provider over HTTP until interrupted
+	if command == "serve" {
+		runServe(*listenFlag, provider, sysInfo, server.Options{
+			Provider:      cfg.CurrentProvider,
+			Model:         cfg.CurrentModel,
+			RecordHistory: !cfg.DisableHistory,
+			Log:           os.Stderr,
+		})
+		os.Exit(0)
+	}
+
+	// Answer MCP tool calls on stdio until the client disconnects
+	if command == "mcp" {
+		if err := mcp.New(provider, sysInfo, cfg.CurrentModel, Version, os.Stderr).Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Ask every question in a batch file without the UI
+	if *batchFlag != "" {
+		if *continueFlag || len(fileFlags) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --batch cannot be combined with --continue or --file\n")
+			os.Exit(1)
+		}
+		items, err := batch.ReadItems(*batchFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Stop sending questions on Ctrl+C, keeping the results written so far
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		batchOpts := batch.Options{
+			Concurrency:       *concurrencyFlag,
+			RequestsPerMinute: *rateFlag,
+			Model:             cfg.CurrentModel,
+		}
+		failed, err := batch.Run(ctx, items, provider, sysInfo, batchOpts, os.Stdout, os.Stderr)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Answered %d of %d question(s)\n", len(items)-failed, len(items))
+		if failed > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// A question picked from history is asked again, bypassing the response cache
+	request := &ai.Request{
+		Question: questionText,
+		SysInfo:  sysInfo,
+		Refresh:  *historyFlag,
+	}
+
+	// Attach piped input as context, and read keys from the terminal instead
+	stdinPiped := input.StdinIsPiped()
+	if stdinPiped {
+		attachment, err := input.ReadStdin(cfg.GetMaxContextBytes())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if attachment != nil {
+			request.Attachments = append(request.Attachments, *attachment)
+		}
+	}
+
+	// Attach files as context
+	for _, spec := range fileFlags {
+		attachment, err := input.ReadFile(spec, cfg.GetMaxContextBytes())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		request.Attachments = append(request.Attachments, *attachment)
+	}
+
 	// Run question UI
-	if err := question.Run(questionText, provider, sysInfo); err != nil {
+	opts := question.Options{
+		ShowReasoning: cfg.ShowReasoning,
+		InputTTY:      stdinPiped,
+		Run:           *runFlag,
+		Edit:          *editFlag || *editLongFlag,
+		Print:         *printFlag,
+		Save:          *saveFlag,
+		Format:        format,
+		Force:         *forceFlag,
+		Model:         cfg.CurrentModel,
+	}
+
+	// Offer a stored answer to the same question instead of asking again.
+	// Skipped when the answer depends on attached context or a previous answer,
+	// or the question was picked from history to be asked again. Scripts always get a fresh answer
+	if !cfg.DisableHistory && !*historyFlag && !*continueFlag && !*printFlag && format == question.FormatTUI && len(request.Attachments) == 0 {
+		if entries, err := history.Load(); err == nil {
+			if entry := history.Find(entries, questionText, sysInfo, cfg.CurrentProvider, cfg.CurrentModel); entry != nil {
+				opts.Stored = entry.Response
+				opts.StoredAt = entry.Timestamp
+			}
+		}
+	}
+
+	response, err := question.Run(request, provider, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Record the answer in the local history, including a stored answer
+	// that was edited. Cached answers were recorded when first asked
+	fresh := response != nil && response != opts.Stored && !response.Cached
+	if (fresh || (response != nil && response.Edited)) && len(response.Commands) > 0 && !cfg.DisableHistory {
+		entry := history.Entry{
+			Question:  questionText,
+			SysInfo:   sysInfo,
+			Provider:  cfg.CurrentProvider,
+			Model:     cfg.CurrentModel,
+			Response:  response,
+			Timestamp: time.Now(),
+		}
+		if err := history.Append(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving history: %v\n", err)
+		}
+	}
+
+	// Remember the response so it can be followed up on
+	if fresh && response.ID != "" {
+		state.LastResponseID = response.ID
+		if err := config.SaveState(state); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving state: %v\n", err)
+		}
+	}
+}
+
+// Create a provider for a model with the API key from the keyring
+func newProvider(cfg *config.Config, providerName, model string, params config.Parameters) (ai.Provider, error) {
+	apiKey, err := config.GetAPIKeyFromKeyring(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve API key: %w", err)
+	}
+	return ai.NewProvider(providerName, apiKey, model, cfg.BaseURL, providerOptions(cfg, model, params))
+}
+
+// Collect the settings a provider is created with for a model
+func providerOptions(cfg *config.Config, model string, params config.Parameters) ai.Options {
+	return ai.Options{
+		Parameters:      params,
+		Capabilities:    cfg.ResolveCapabilities(model),
+		UseResponsesAPI: cfg.OpenAIResponsesAPI,
+		Google:          cfg.Google,
+	}
+}
+
+// Run the HTTP server with a token generated for this run. The URL and token
+// are printed to stdout as JSON for the editor plugin that started it
+func runServe(address string, provider ai.Provider, sysInfo *system.SystemInfo, opts server.Options) {
+	token, err := server.GenerateToken()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Token = token
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	url := "http://" + listener.Addr().String()
+	if host, _, _ := net.SplitHostPort(address); !net.ParseIP(host).IsLoopback() && host != "localhost" {
+		fmt.Fprintf(os.Stderr, "Warning: listening on %s, which may be reachable from other machines\n", listener.Addr())
+	}
+	fmt.Fprintf(os.Stderr, "Serving %s on %s (Ctrl+C to stop)\n", provider.GetName(), url)
+	json.NewEncoder(os.Stdout).Encode(map[string]string{"url": url, "token": token})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := server.Serve(ctx, listener, server.New(provider, sysInfo, opts)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Manage gateway users, or serve the configured providers to them until interrupted
+func runGateway(args []string) {
+	flags := flag.NewFlagSet("how gateway", flag.ExitOnError)
+	listenFlag := flags.String("listen", "127.0.0.1:8080", "Address to listen on")
+	addUserFlag := flags.String("add-user", "", "Add a user and print their token")
+	removeUserFlag := flags.String("remove-user", "", "Remove a user")
+	usersFlag := flags.Bool("users", false, "List users with their quotas and usage")
+	modelsFlag := flags.String("models", "", "Comma-separated models a new user may use")
+	dailyRequestsFlag := flags.Int("daily-requests", 0, "Requests a new user may make per day, 0 for no limit")
+	monthlyTokensFlag := flags.Int64("monthly-tokens", 0, "Tokens a new user may use per month, 0 for no limit")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: how gateway [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Serve the configured providers as an OpenAI-compatible endpoint\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --listen ADDR          Address to listen on (default 127.0.0.1:8080)\n")
+		fmt.Fprintf(os.Stderr, "  --add-user NAME        Add a user and print their token\n")
+		fmt.Fprintf(os.Stderr, "  --models LIST          Comma-separated models the new user may use (default all)\n")
+		fmt.Fprintf(os.Stderr, "  --daily-requests N     Requests the new user may make per day (default no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --monthly-tokens N     Tokens the new user may use per month (default no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --remove-user NAME     Remove a user\n")
+		fmt.Fprintf(os.Stderr, "  --users                List users with their quotas and usage\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help             Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  how gateway --add-user alice --models gpt-5-mini --monthly-tokens 2000000\n")
+		fmt.Fprintf(os.Stderr, "  how gateway --listen 0.0.0.0:8080\n")
+	}
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	gatewayConfig, err := gateway.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading gateway config: %v\n", err)
+		os.Exit(1)
+	}
+	// Served models need the keyring, so are only resolved when used
+	resolveModels := func() map[string]string {
+		models, err := gateway.ResolveModels(cfg, gatewayConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return models
+	}
+	usagePath, err := gateway.GetUsagePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	usage, err := gateway.OpenUsage(usagePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case *addUserFlag != "":
+		var allowed []string
+		for _, model := range strings.Split(*modelsFlag, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				allowed = append(allowed, model)
+			}
+		}
+		if len(allowed) > 0 {
+			models := resolveModels()
+			for _, model := range allowed {
+				if _, ok := models[model]; !ok {
+					fmt.Fprintf(os.Stderr, "Error: the gateway does not serve %s\n", model)
+					os.Exit(1)
+				}
+			}
+		}
+		token, err := gatewayConfig.AddUser(*addUserFlag, allowed, *dailyRequestsFlag, *monthlyTokensFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := gateway.SaveConfig(gatewayConfig); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving gateway config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Added %s. Their token is shown only once:\n", *addUserFlag)
+		fmt.Println(token)
+		return
+	case *removeUserFlag != "":
+		if err := gatewayConfig.RemoveUser(*removeUserFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := gateway.SaveConfig(gatewayConfig); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving gateway config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %s\n", *removeUserFlag)
+		return
+	case *usersFlag:
+		if len(gatewayConfig.Users) == 0 {
+			fmt.Println("No gateway users")
+			return
+		}
+		limit := func(value int64) string {
+			if value == 0 {
+				return "no limit"
+			}
+			return fmt.Sprint(value)
+		}
+		for _, user := range gatewayConfig.Users {
+			requests, tokens := usage.Totals(user.Name)
+			allowed := "all models"
+			if len(user.Models) > 0 {
+				allowed = strings.Join(user.Models, ", ")
+			}
+			fmt.Printf("%s (%s)\n", user.Name, allowed)
+			fmt.Printf("  requests today: %d of %s\n", requests, limit(int64(user.DailyRequests)))
+			fmt.Printf("  tokens this month: %d of %s\n", tokens, limit(user.MonthlyTokens))
+		}
+		return
+	}
+
+	if len(gatewayConfig.Users) == 0 {
+		fmt.Fprintf(os.Stderr, "No gateway users. Add one with 'how gateway --add-user NAME'.\n")
+		os.Exit(1)
+	}
+	models := resolveModels()
+	if len(models) == 0 {
+		fmt.Fprintf(os.Stderr, "No models to serve. Run 'how --configure' to add an API key.\n")
+		os.Exit(1)
+	}
+
+	// Providers use the generation parameters configured for their model
+	factory := func(providerName, model string) (ai.Provider, error) {
+		params := cfg.ResolveParameters(providerName, model)
+		if err := params.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid parameters for %s: %w", model, err)
+		}
+		return newProvider(cfg, providerName, model, params)
+	}
+
+	listener, err := net.Listen("tcp", *listenFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Serving %d models to %d users on http://%s/v1 (Ctrl+C to stop)\n", len(models), len(gatewayConfig.Users), listener.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	handler := gateway.New(gatewayConfig.Users, models, factory, usage, os.Stderr)
+	if err := server.Serve(ctx, listener, handler); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Pick the output format from flags. Without one, the UI is shown only when
+// stdout is a terminal, or with --print, which draws on the terminal directly
+func outputFormat(jsonOutput, raw, quiet, print bool) (question.Format, error) {
+	chosen := 0
+	format := question.FormatTUI
+	for _, option := range []struct {
+		set    bool
+		format question.Format
+	}{{jsonOutput, question.FormatJSON}, {raw, question.FormatRaw}, {quiet, question.FormatQuiet}} {
+		if option.set {
+			chosen++
+			format = option.format
+		}
+	}
+	switch {
+	case chosen > 1:
+		return format, fmt.Errorf("only one of --json, --raw and --quiet can be used")
+	case chosen == 1 && print:
+		return format, fmt.Errorf("--print cannot be combined with --json, --raw or --quiet")
+	case chosen == 0 && !print && !input.StdoutIsTerminal():
+		return question.FormatPlain, nil
+	}
+	return format, nil
+}
+
+// Open the response cache with the configured limits
+func openResponseCache(cfg *config.Config) (*cache.Cache, error) {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(filepath.Join(cacheDir, "responses"), cfg.Cache.GetTTL(), cfg.Cache.GetMaxBytes())
+}
+
+// Show a saved snippet in the question UI, checked against the current system
+func recallSnippet(name string, opts question.Options) {
+	snippets, err := saved.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading saved snippets: %v\n", err)
+		os.Exit(1)
+	}
+	snippet := saved.Find(snippets, name)
+	if snippet == nil {
+		fmt.Fprintf(os.Stderr, "No saved snippet named @%s. Run 'how --saved' to list them.\n", name)
+		os.Exit(1)
+	}
+
+	sysInfo, err := system.DetectSystem()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error detecting system: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts.Stored = snippet.Response
+	opts.StoredAt = snippet.Created
+	opts.StoredName = snippet.Name
+	opts.StoredTags = snippet.Tags
+	request := &ai.Request{
+		Question: snippet.Question,
+		SysInfo:  sysInfo,
+	}
+	if _, err := question.Run(request, nil, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}